@@ -0,0 +1,386 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/subscription/armsubscription"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/opengovern/og-azure-describer/azure/describer"
+)
+
+const (
+	ReaderRoleDefinitionID        = "acdd72a7-3385-48ef-bd42-f606fba81ae7"
+	BillingReaderRoleDefinitionID = "fa23ad8b-c56e-40d8-ac0c-ce449e1d2c64"
+	ContributorRoleDefinitionID   = "b24988ac-6180-42a0-ab88-20f7382dd24c"
+	OwnerRoleDefinitionID         = "8e3af657-a8ff-443c-a75c-2fe8c4bcb635"
+)
+
+// entraGraphPermissions are the Microsoft Graph application permissions the Entra ID describers need, any of
+// the permissions of a resource type is enough. Directory.Read.All covers the directory objects.
+var entraGraphPermissions = map[string][]string{
+	"Microsoft.Entra/users":                     {"User.Read.All", "Directory.Read.All"},
+	"Microsoft.Entra/groups":                    {"Group.Read.All", "Directory.Read.All"},
+	"Microsoft.Entra/groupMemberships":          {"GroupMember.Read.All", "Group.Read.All", "Directory.Read.All"},
+	"Microsoft.Entra/devices":                   {"Device.Read.All", "Directory.Read.All"},
+	"Microsoft.Entra/serviceprincipals":         {"Application.Read.All", "Directory.Read.All"},
+	"Microsoft.Entra/applications":              {"Application.Read.All", "Directory.Read.All"},
+	"Microsoft.Entra/appRegistrations":          {"Application.Read.All", "Directory.Read.All"},
+	"Microsoft.Entra/enterpriseApplication":     {"Application.Read.All", "Directory.Read.All"},
+	"Microsoft.Entra/managedIdentity":           {"Application.Read.All", "Directory.Read.All"},
+	"Microsoft.Entra/microsoftApplication":      {"Application.Read.All", "Directory.Read.All"},
+	"Microsoft.Entra/domains":                   {"Domain.Read.All", "Directory.Read.All"},
+	"Microsoft.Entra/tenant":                    {"Organization.Read.All", "Directory.Read.All"},
+	"Microsoft.Entra/directoryroles":            {"RoleManagement.Read.Directory", "Directory.Read.All"},
+	"Microsoft.Entra/directorysettings":         {"Directory.Read.All"},
+	"Microsoft.Entra/identityproviders":         {"IdentityProvider.Read.All"},
+	"Microsoft.Entra/securitydefaultspolicy":    {"Policy.Read.All"},
+	"Microsoft.Entra/authorizationpolicy":       {"Policy.Read.All"},
+	"Microsoft.Entra/conditionalaccesspolicy":   {"Policy.Read.All"},
+	"Microsoft.Entra/adminconsentrequestpolicy": {"Policy.Read.All"},
+	"Microsoft.Entra/signInReports":             {"AuditLog.Read.All"},
+	"Microsoft.Entra/directoryauditreport":      {"AuditLog.Read.All"},
+	"Microsoft.Entra/userregistrationdetails":   {"AuditLog.Read.All"},
+}
+
+// AccessReport is the preflight check of a credential, it tells which resource types can be described before
+// onboarding a tenant.
+type AccessReport struct {
+	TenantID string `json:"tenant_id"`
+	// PrincipalID is the object id of the identity of the credential.
+	PrincipalID   string               `json:"principal_id,omitempty"`
+	Subscriptions []SubscriptionAccess `json:"subscriptions"`
+	EntraID       EntraIDAccess        `json:"entra_id"`
+}
+
+// SubscriptionAccess is the access of the credential to a subscription.
+type SubscriptionAccess struct {
+	SubscriptionID string `json:"subscription_id"`
+	DisplayName    string `json:"display_name,omitempty"`
+	State          string `json:"state,omitempty"`
+	// Error is set if the subscription could not be checked, e.g. it is not visible to the credential.
+	Error string `json:"error,omitempty"`
+	// Reader tells whether the Reader role, or the Contributor or Owner role including it, is assigned to the
+	// principal on the subscription or above. The assignments on its resource groups and resources do not count.
+	Reader        RoleAssignmentStatus `json:"reader"`
+	BillingReader RoleAssignmentStatus `json:"billing_reader"`
+	// UnregisteredProviders are the resource providers of the supported resource types which are not
+	// registered in the subscription.
+	UnregisteredProviders []string              `json:"unregistered_providers,omitempty"`
+	FailingResourceTypes  []FailingResourceType `json:"failing_resource_types,omitempty"`
+}
+
+type RoleAssignmentStatus string
+
+const (
+	RoleAssigned    RoleAssignmentStatus = "assigned"
+	RoleNotAssigned RoleAssignmentStatus = "not_assigned"
+	// RoleUnknown is reported when the principal of the credential could not be resolved from its token.
+	RoleUnknown RoleAssignmentStatus = "unknown"
+)
+
+// EntraIDAccess is the access of the credential to the Microsoft Graph API of the tenant.
+type EntraIDAccess struct {
+	Error         string `json:"error,omitempty"`
+	DefaultDomain string `json:"default_domain,omitempty"`
+	// Permissions are the Graph permissions granted to the credential.
+	Permissions          []string              `json:"permissions"`
+	FailingResourceTypes []FailingResourceType `json:"failing_resource_types,omitempty"`
+}
+
+type FailingResourceType struct {
+	ResourceType string `json:"resource_type"`
+	Reason       string `json:"reason"`
+}
+
+// ListSubscriptions returns the IDs of the subscriptions the credential can see.
+func ListSubscriptions(ctx context.Context, cfg AuthConfig, authType AuthType, azureAuthLoc string) ([]string, error) {
+	c, err := CloudFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cred, err := NewTokenCredential(cfg, authType, azureAuthLoc)
+	if err != nil {
+		return nil, err
+	}
+	ctx = describer.WithCloud(ctx, c)
+	subscriptionClient, err := armsubscription.NewSubscriptionsClient(cred, describer.ClientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return listSubscriptions(ctx, subscriptionClient)
+}
+
+func listSubscriptions(ctx context.Context, client *armsubscription.SubscriptionsClient) ([]string, error) {
+	var subscriptions []string
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list subscriptions: %w", err)
+		}
+		for _, s := range page.Value {
+			if s.SubscriptionID != nil {
+				subscriptions = append(subscriptions, *s.SubscriptionID)
+			}
+		}
+	}
+	return subscriptions, nil
+}
+
+// CheckAccess builds the AccessReport of the credential for the subscriptions, all the subscriptions it can
+// see if none is given. The failures of the individual checks are reported in it rather than returned.
+func CheckAccess(ctx context.Context, cfg AuthConfig, authType AuthType, azureAuthLoc string, subscriptions []string) (*AccessReport, error) {
+	c, err := CloudFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cred, err := NewTokenCredential(cfg, authType, azureAuthLoc)
+	if err != nil {
+		return nil, err
+	}
+	ctx = describer.WithCloud(ctx, c)
+
+	report := &AccessReport{TenantID: cfg.TenantID, PrincipalID: cfg.ObjectID}
+	armClaims, err := tokenClaims(ctx, cred, c.Configuration.Services[cloud.ResourceManager].Audience+"/.default")
+	if err != nil {
+		return nil, fmt.Errorf("get resource manager token: %w", err)
+	}
+	if tid, ok := armClaims["tid"].(string); ok {
+		report.TenantID = tid
+	}
+	if oid, ok := armClaims["oid"].(string); ok {
+		report.PrincipalID = oid
+	}
+
+	subscriptionClient, err := armsubscription.NewSubscriptionsClient(cred, describer.ClientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if len(subscriptions) == 0 {
+		if subscriptions, err = listSubscriptions(ctx, subscriptionClient); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, subscription := range subscriptions {
+		access := SubscriptionAccess{SubscriptionID: subscription}
+		if err := checkSubscriptionAccess(ctx, cred, subscriptionClient, report.PrincipalID, &access); err != nil {
+			access.Error = err.Error()
+		}
+		access.FailingResourceTypes = failingSubscriptionResourceTypes(access)
+		report.Subscriptions = append(report.Subscriptions, access)
+	}
+
+	report.EntraID = checkEntraIDAccess(ctx, cred, c)
+	return report, nil
+}
+
+func checkSubscriptionAccess(ctx context.Context, cred azcore.TokenCredential, client *armsubscription.SubscriptionsClient, principalID string, access *SubscriptionAccess) error {
+	sub, err := client.Get(ctx, access.SubscriptionID, nil)
+	if err != nil {
+		return err
+	}
+	if sub.DisplayName != nil {
+		access.DisplayName = *sub.DisplayName
+	}
+	if sub.State != nil {
+		access.State = string(*sub.State)
+	}
+
+	access.Reader, access.BillingReader = RoleUnknown, RoleUnknown
+	if principalID != "" {
+		access.Reader, access.BillingReader = RoleNotAssigned, RoleNotAssigned
+		roles, err := armauthorization.NewRoleAssignmentsClient(access.SubscriptionID, cred, describer.ClientOptions(ctx))
+		if err != nil {
+			return err
+		}
+		// includes the assignments of the groups of the principal
+		pager := roles.NewListForSubscriptionPager(&armauthorization.RoleAssignmentsClientListForSubscriptionOptions{
+			Filter: to.Ptr(fmt.Sprintf("assignedTo('%s')", principalID)),
+		})
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return fmt.Errorf("list role assignments: %w", err)
+			}
+			for _, assignment := range page.Value {
+				if assignment.Properties == nil || assignment.Properties.RoleDefinitionID == nil || assignment.Properties.Scope == nil ||
+					!coversSubscription(*assignment.Properties.Scope, access.SubscriptionID) {
+					continue
+				}
+				roleDefinitionID := strings.ToLower(*assignment.Properties.RoleDefinitionID)
+				switch {
+				case strings.HasSuffix(roleDefinitionID, ReaderRoleDefinitionID),
+					strings.HasSuffix(roleDefinitionID, ContributorRoleDefinitionID),
+					strings.HasSuffix(roleDefinitionID, OwnerRoleDefinitionID):
+					access.Reader = RoleAssigned
+				case strings.HasSuffix(roleDefinitionID, BillingReaderRoleDefinitionID):
+					access.BillingReader = RoleAssigned
+				}
+			}
+		}
+	}
+
+	providers, err := armresources.NewProvidersClient(access.SubscriptionID, cred, describer.ClientOptions(ctx))
+	if err != nil {
+		return err
+	}
+	registered := map[string]bool{}
+	providerPager := providers.NewListPager(nil)
+	for providerPager.More() {
+		page, err := providerPager.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("list resource providers: %w", err)
+		}
+		for _, p := range page.Value {
+			if p.Namespace != nil {
+				registered[strings.ToLower(*p.Namespace)] = p.RegistrationState != nil && strings.EqualFold(*p.RegistrationState, "Registered")
+			}
+		}
+	}
+	unregistered := map[string]bool{}
+	for _, resourceType := range ListResourceTypes() {
+		namespace := strings.Split(resourceType, "/")[0]
+		// the namespaces unknown to the subscription are not providers, e.g. Microsoft.Entra
+		if isRegistered, ok := registered[strings.ToLower(namespace)]; ok && !isRegistered {
+			unregistered[namespace] = true
+		}
+	}
+	for namespace := range unregistered {
+		access.UnregisteredProviders = append(access.UnregisteredProviders, namespace)
+	}
+	sort.Strings(access.UnregisteredProviders)
+	return nil
+}
+
+// coversSubscription reports whether a role assigned at scope applies to the whole subscription, i.e. scope is
+// the subscription or one of its ancestors. The subscription assignments list the ones of the management groups
+// above it only.
+func coversSubscription(scope, subscriptionID string) bool {
+	scope = strings.TrimSuffix(strings.ToLower(scope), "/")
+	return scope == "" ||
+		scope == "/subscriptions/"+strings.ToLower(subscriptionID) ||
+		strings.HasPrefix(scope, "/providers/microsoft.management/managementgroups/")
+}
+
+// failingSubscriptionResourceTypes lists the subscription resource types that cannot be described with access.
+// The roles whose assignment is unknown are not reported as missing.
+func failingSubscriptionResourceTypes(access SubscriptionAccess) []FailingResourceType {
+	var failing []FailingResourceType
+	for _, resourceType := range ListResourceTypes() {
+		if isEntraIDResourceType(resourceType) {
+			continue
+		}
+		reason := ""
+		namespace := strings.Split(resourceType, "/")[0]
+		switch {
+		case access.Error != "":
+			reason = "subscription is not accessible"
+		case resourceTypes[resourceType].CostDiscovery && access.BillingReader == RoleNotAssigned:
+			reason = "Billing Reader role is not assigned"
+		case access.Reader == RoleNotAssigned:
+			reason = "Reader role is not assigned"
+		case containsFold(access.UnregisteredProviders, namespace):
+			reason = fmt.Sprintf("resource provider %s is not registered", namespace)
+		}
+		if reason != "" {
+			failing = append(failing, FailingResourceType{ResourceType: resourceType, Reason: reason})
+		}
+	}
+	return failing
+}
+
+func checkEntraIDAccess(ctx context.Context, cred azcore.TokenCredential, c describer.Cloud) EntraIDAccess {
+	access := EntraIDAccess{Permissions: []string{}}
+	claims, err := tokenClaims(ctx, cred, c.GraphScope())
+	if err == nil {
+		access.Permissions = grantedPermissions(claims)
+		var data *EntraIdExtraData
+		data, err = checkEntraIDPermission(ctx, cred, c)
+		if data != nil && data.DefaultDomain != nil {
+			access.DefaultDomain = *data.DefaultDomain
+		}
+	}
+	if err != nil {
+		access.Error = err.Error()
+	}
+
+	for _, resourceType := range ListResourceTypes() {
+		if !isEntraIDResourceType(resourceType) {
+			continue
+		}
+		required := entraGraphPermissions[resourceType]
+		switch {
+		case access.Error != "":
+			access.FailingResourceTypes = append(access.FailingResourceTypes, FailingResourceType{ResourceType: resourceType, Reason: "Microsoft Graph is not accessible"})
+		case len(required) > 0 && !containsAnyFold(access.Permissions, required):
+			access.FailingResourceTypes = append(access.FailingResourceTypes, FailingResourceType{
+				ResourceType: resourceType,
+				Reason:       fmt.Sprintf("one of the %s permissions is required", strings.Join(required, ", ")),
+			})
+		}
+	}
+	return access
+}
+
+func isEntraIDResourceType(resourceType string) bool {
+	return strings.HasPrefix(strings.ToLower(resourceType), "microsoft.entra/")
+}
+
+// tokenClaims returns the claims of an access token of the credential for scope. The token is not verified,
+// it is only inspected.
+func tokenClaims(ctx context.Context, cred azcore.TokenCredential, scope string) (jwt.MapClaims, error) {
+	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{scope}})
+	if err != nil {
+		return nil, err
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token.Token, claims); err != nil {
+		return nil, fmt.Errorf("parse access token: %w", err)
+	}
+	return claims, nil
+}
+
+// grantedPermissions returns the application permissions (roles) and delegated scopes (scp) of a token.
+func grantedPermissions(claims jwt.MapClaims) []string {
+	permissions := []string{}
+	if roles, ok := claims["roles"].([]any); ok {
+		for _, role := range roles {
+			if s, ok := role.(string); ok {
+				permissions = append(permissions, s)
+			}
+		}
+	}
+	if scp, ok := claims["scp"].(string); ok {
+		permissions = append(permissions, strings.Fields(scp)...)
+	}
+	sort.Strings(permissions)
+	return permissions
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAnyFold(list []string, values []string) bool {
+	for _, v := range values {
+		if containsFold(list, v) {
+			return true
+		}
+	}
+	return false
+}
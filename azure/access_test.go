@@ -0,0 +1,76 @@
+package azure
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestFailingSubscriptionResourceTypes(t *testing.T) {
+	reasons := func(access SubscriptionAccess) map[string]string {
+		m := map[string]string{}
+		for _, f := range failingSubscriptionResourceTypes(access) {
+			m[f.ResourceType] = f.Reason
+		}
+		return m
+	}
+
+	failing := reasons(SubscriptionAccess{Reader: RoleAssigned, BillingReader: RoleAssigned, UnregisteredProviders: []string{"Microsoft.Compute"}})
+	if !strings.Contains(failing["Microsoft.Compute/disks"], "Microsoft.Compute") {
+		t.Errorf("expected disks to fail for the unregistered provider, got %q", failing["Microsoft.Compute/disks"])
+	}
+	if _, ok := failing["Microsoft.Network/virtualNetworks"]; ok {
+		t.Error("expected virtual networks not to fail")
+	}
+	for resourceType := range failing {
+		if isEntraIDResourceType(resourceType) {
+			t.Errorf("unexpected Entra ID resource type %s", resourceType)
+		}
+	}
+
+	failing = reasons(SubscriptionAccess{Reader: RoleAssigned, BillingReader: RoleNotAssigned})
+	for resourceType, reason := range failing {
+		if !resourceTypes[resourceType].CostDiscovery || !strings.Contains(reason, "Billing Reader") {
+			t.Errorf("%s: unexpected reason %q", resourceType, reason)
+		}
+	}
+}
+
+func TestCoversSubscription(t *testing.T) {
+	for scope, want := range map[string]bool{
+		"/": true,
+		"/subscriptions/00000000-0000-0000-0000-00000000000A":                       true,
+		"/providers/Microsoft.Management/managementGroups/root":                     true,
+		"/subscriptions/00000000-0000-0000-0000-00000000000a/resourceGroups/rg":     false,
+		"/subscriptions/00000000-0000-0000-0000-00000000000a/resourceGroups/rg/x/y": false,
+		"/subscriptions/00000000-0000-0000-0000-00000000000b":                       false,
+	} {
+		if got := coversSubscription(scope, "00000000-0000-0000-0000-00000000000a"); got != want {
+			t.Errorf("%s: got %v, want %v", scope, got, want)
+		}
+	}
+
+	if failing := failingSubscriptionResourceTypes(SubscriptionAccess{Reader: RoleUnknown, BillingReader: RoleUnknown}); len(failing) != 0 {
+		t.Errorf("expected no failing resource type when the roles are unknown, got %v", failing)
+	}
+}
+
+func TestGrantedPermissions(t *testing.T) {
+	got := grantedPermissions(jwt.MapClaims{
+		"roles": []any{"User.Read.All", "Policy.Read.All"},
+		"scp":   "Directory.Read.All",
+	})
+	if strings.Join(got, ",") != "Directory.Read.All,Policy.Read.All,User.Read.All" {
+		t.Errorf("unexpected permissions %v", got)
+	}
+	if !containsAnyFold(got, entraGraphPermissions["Microsoft.Entra/groups"]) {
+		t.Error("expected Directory.Read.All to grant the groups")
+	}
+
+	for _, resourceType := range ListResourceTypes() {
+		if _, ok := entraGraphPermissions[resourceType]; isEntraIDResourceType(resourceType) && !ok {
+			t.Errorf("no Graph permission for %s", resourceType)
+		}
+	}
+}
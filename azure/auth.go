@@ -1,11 +1,20 @@
 package azure
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"github.com/opengovern/og-azure-describer/azure/describer"
 )
 
 type AuthConfig struct {
@@ -21,6 +30,53 @@ type AuthConfig struct {
 	Password            string
 	EnvironmentName     string
 	Resource            string
+	// FederatedTokenFile is the projected service account token used by the workload identity credential.
+	FederatedTokenFile string
+
+	// The endpoints below override the ones of the cloud named by EnvironmentName, e.g. for Azure Stack.
+	ResourceManagerEndpoint      string
+	ResourceManagerAudience      string
+	ActiveDirectoryAuthorityHost string
+	GraphEndpoint                string
+	StorageEndpointSuffix        string
+}
+
+// CloudFromConfig returns the cloud named by cfg.EnvironmentName with the endpoints overridden by cfg.
+func CloudFromConfig(cfg AuthConfig) (describer.Cloud, error) {
+	c, err := describer.CloudFromName(cfg.EnvironmentName)
+	if err != nil {
+		return describer.Cloud{}, err
+	}
+
+	services := map[cloud.ServiceName]cloud.ServiceConfiguration{}
+	for name, svc := range c.Configuration.Services {
+		services[name] = svc
+	}
+	c.Configuration.Services = services
+
+	if cfg.ActiveDirectoryAuthorityHost != "" {
+		c.Configuration.ActiveDirectoryAuthorityHost = cfg.ActiveDirectoryAuthorityHost
+	}
+	if cfg.ResourceManagerEndpoint != "" {
+		arm := services[cloud.ResourceManager]
+		arm.Endpoint = cfg.ResourceManagerEndpoint
+		if cfg.ResourceManagerAudience == "" {
+			arm.Audience = cfg.ResourceManagerEndpoint
+		}
+		services[cloud.ResourceManager] = arm
+	}
+	if cfg.ResourceManagerAudience != "" {
+		arm := services[cloud.ResourceManager]
+		arm.Audience = cfg.ResourceManagerAudience
+		services[cloud.ResourceManager] = arm
+	}
+	if cfg.GraphEndpoint != "" {
+		c.GraphEndpoint = strings.TrimSuffix(cfg.GraphEndpoint, "/")
+	}
+	if cfg.StorageEndpointSuffix != "" {
+		c.StorageEndpointSuffix = strings.TrimPrefix(cfg.StorageEndpointSuffix, ".")
+	}
+	return c, nil
 }
 
 func NewAuthorizerFromConfig(cfg AuthConfig) (autorest.Authorizer, error) {
@@ -78,6 +134,122 @@ func GetSettingsFromConfig(cfg AuthConfig) (s auth.EnvironmentSettings, err erro
 	return
 }
 
+// NewTokenCredential builds the credential of the given auth type.
+// With AuthEnv the credential is picked from the fields set in cfg: client secret, client certificate,
+// federated token file and then username/password.
+// The credential authenticates against the authority host of the cloud of cfg.
+func NewTokenCredential(cfg AuthConfig, authType AuthType, azureAuthLoc string) (azcore.TokenCredential, error) {
+	c, err := CloudFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clientOptions := policy.ClientOptions{Cloud: c.Configuration}
+
+	switch authType {
+	case AuthEnv:
+		return newCredentialFromConfig(cfg, clientOptions)
+	case AuthFile:
+		fileCfg, err := authConfigFromFile(azureAuthLoc)
+		if err != nil {
+			return nil, err
+		}
+		return newCredentialFromConfig(fileCfg, clientOptions)
+	case AuthCLI:
+		// the Azure CLI uses the cloud set with az cloud set
+		return azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{
+			TenantID: cfg.TenantID,
+		})
+	case AuthManagedIdentity:
+		opts := &azidentity.ManagedIdentityCredentialOptions{ClientOptions: clientOptions}
+		if cfg.ClientID != "" {
+			opts.ID = azidentity.ClientID(cfg.ClientID)
+		}
+		return azidentity.NewManagedIdentityCredential(opts)
+	case AuthWorkloadIdentity:
+		// Empty fields are read from the AZURE_* variables injected by the AKS workload identity webhook
+		return azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions: clientOptions,
+			TenantID:      cfg.TenantID,
+			ClientID:      cfg.ClientID,
+			TokenFilePath: cfg.FederatedTokenFile,
+		})
+	default:
+		return nil, fmt.Errorf("invalid auth type: %s", authType)
+	}
+}
+
+// authFile is the SDK auth file written by az ad sp create-for-rbac --sdk-auth.
+type authFile struct {
+	ClientID                  string `json:"clientId"`
+	ClientSecret              string `json:"clientSecret"`
+	ClientCertificate         string `json:"clientCertificate"`
+	ClientCertificatePassword string `json:"clientCertificatePassword"`
+	TenantID                  string `json:"tenantId"`
+}
+
+// authConfigFromFile reads the SDK auth file at path, or at AZURE_AUTH_LOCATION if path is empty.
+func authConfigFromFile(path string) (AuthConfig, error) {
+	if path == "" {
+		path = os.Getenv(AzureAuthLocation)
+	}
+	if path == "" {
+		return AuthConfig{}, fmt.Errorf("no auth file location, %s is not set", AzureAuthLocation)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return AuthConfig{}, fmt.Errorf("read auth file: %w", err)
+	}
+	// the Azure CLI on Windows writes the file with a UTF-8 BOM
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	var f authFile
+	if err := json.Unmarshal(content, &f); err != nil {
+		return AuthConfig{}, fmt.Errorf("parse auth file: %w", err)
+	}
+	return AuthConfig{
+		TenantID:            f.TenantID,
+		ClientID:            f.ClientID,
+		ClientSecret:        f.ClientSecret,
+		CertificatePath:     f.ClientCertificate,
+		CertificatePassword: f.ClientCertificatePassword,
+	}, nil
+}
+
+func newCredentialFromConfig(cfg AuthConfig, clientOptions policy.ClientOptions) (azcore.TokenCredential, error) {
+	switch {
+	case cfg.ClientSecret != "":
+		return azidentity.NewClientSecretCredential(cfg.TenantID, cfg.ClientID, cfg.ClientSecret, &azidentity.ClientSecretCredentialOptions{
+			ClientOptions: clientOptions,
+		})
+	case cfg.CertificatePath != "":
+		certData, err := os.ReadFile(cfg.CertificatePath)
+		if err != nil {
+			return nil, fmt.Errorf("read certificate: %w", err)
+		}
+		certs, key, err := azidentity.ParseCertificates(certData, []byte(cfg.CertificatePassword))
+		if err != nil {
+			return nil, fmt.Errorf("parse certificate: %w", err)
+		}
+		return azidentity.NewClientCertificateCredential(cfg.TenantID, cfg.ClientID, certs, key, &azidentity.ClientCertificateCredentialOptions{
+			ClientOptions: clientOptions,
+		})
+	case cfg.FederatedTokenFile != "":
+		return azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions: clientOptions,
+			TenantID:      cfg.TenantID,
+			ClientID:      cfg.ClientID,
+			TokenFilePath: cfg.FederatedTokenFile,
+		})
+	case cfg.Username != "" && cfg.Password != "":
+		return azidentity.NewUsernamePasswordCredential(cfg.TenantID, cfg.ClientID, cfg.Username, cfg.Password, &azidentity.UsernamePasswordCredentialOptions{
+			ClientOptions: clientOptions,
+		})
+	default:
+		return nil, fmt.Errorf("no client secret, certificate, federated token or username/password in auth config")
+	}
+}
+
 type SubscriptionConfig struct {
 	SubscriptionID  string `json:"subscriptionId"`
 	TenantID        string `json:"tenantId"`
@@ -89,6 +261,16 @@ type SubscriptionConfig struct {
 	CertificatePass string `json:"certificatePass"`
 	Username        string `json:"username"`
 	Password        string `json:"password"`
+	// AuthType is one of the AuthType values, AuthEnv if empty.
+	AuthType           string `json:"authType"`
+	FederatedTokenFile string `json:"federatedTokenFile"`
+	// EnvironmentName is the name of the Azure cloud, AzurePublicCloud if empty.
+	EnvironmentName              string `json:"environmentName"`
+	ResourceManagerEndpoint      string `json:"resourceManagerEndpoint"`
+	ResourceManagerAudience      string `json:"resourceManagerAudience"`
+	ActiveDirectoryAuthorityHost string `json:"activeDirectoryAuthorityHost"`
+	GraphEndpoint                string `json:"graphEndpoint"`
+	StorageEndpointSuffix        string `json:"storageEndpointSuffix"`
 }
 
 func SubscriptionConfigFromMap(m map[string]any) (SubscriptionConfig, error) {
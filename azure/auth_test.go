@@ -0,0 +1,40 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/opengovern/og-azure-describer/azure/describer"
+)
+
+func TestCloudFromConfig(t *testing.T) {
+	c, err := CloudFromConfig(AuthConfig{EnvironmentName: "AzureUSGovernmentCloud"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != describer.USGovernmentCloud.Name || c.ResourceManagerEndpoint() != "https://management.usgovcloudapi.net" {
+		t.Errorf("unexpected cloud: %s %s", c.Name, c.ResourceManagerEndpoint())
+	}
+
+	c, err = CloudFromConfig(AuthConfig{
+		ResourceManagerEndpoint: "https://management.local.azurestack.external/",
+		StorageEndpointSuffix:   ".local.azurestack.external",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Configuration.Services[cloud.ResourceManager].Audience; got != "https://management.local.azurestack.external/" {
+		t.Errorf("audience: got %s", got)
+	}
+	if got := c.StorageEndpoint("account", "blob"); got != "https://account.blob.local.azurestack.external/" {
+		t.Errorf("storage endpoint: got %s", got)
+	}
+	// the overrides must not leak into the shared cloud configuration
+	if got := cloud.AzurePublic.Services[cloud.ResourceManager].Endpoint; got != "https://management.azure.com" {
+		t.Errorf("public cloud was modified: %s", got)
+	}
+
+	if _, err := CloudFromConfig(AuthConfig{EnvironmentName: "AzureGermanCloud"}); err == nil {
+		t.Error("expected an error for an unknown cloud")
+	}
+}
@@ -2,7 +2,7 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/datalake-analytics/armdatalakeanalytics"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/datalake-store/armdatalakestore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
@@ -11,14 +11,14 @@ import (
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func DataLakeAnalyticsAccount(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armdatalakeanalytics.NewClientFactory(subscription, cred, nil)
+func DataLakeAnalyticsAccount(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armdatalakeanalytics.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	client := clientFactory.NewAccountsClient()
 
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -93,14 +93,14 @@ func getDataLakeAnalyticsAccount(ctx context.Context, account *armdatalakeanalyt
 	return &resource, nil
 }
 
-func DataLakeStore(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armdatalakestore.NewClientFactory(subscription, cred, nil)
+func DataLakeStore(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armdatalakestore.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	client := clientFactory.NewAccountsClient()
 
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -4,14 +4,14 @@ import (
 	"context"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/alertsmanagement/armalertsmanagement"
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func AlertManagement(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+func AlertManagement(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
 
-	clientFactory, err := armalertsmanagement.NewClientFactory(subscription, cred, nil)
+	clientFactory, err := armalertsmanagement.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
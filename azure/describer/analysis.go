@@ -2,15 +2,15 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/analysisservices/armanalysisservices"
 	"strings"
 
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func AnalysisService(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armanalysisservices.NewClientFactory(subscription, cred, nil)
+func AnalysisService(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armanalysisservices.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -4,21 +4,21 @@ import (
 	"context"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/apimanagement/armapimanagement"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
 
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func APIManagement(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armapimanagement.NewClientFactory(subscription, cred, nil)
+func APIManagement(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armapimanagement.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	client := clientFactory.NewServiceClient()
 
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -76,9 +76,9 @@ func getAPIMangement(ctx context.Context, diagnosticClient *armmonitor.Diagnosti
 	return &resource, nil
 }
 
-func APIManagementBackend(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+func APIManagementBackend(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
 
-	clientFactory, err := armapimanagement.NewClientFactory(subscription, cred, nil)
+	clientFactory, err := armapimanagement.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -96,6 +96,9 @@ func APIManagementBackend(ctx context.Context, cred *azidentity.ClientSecretCred
 		for _, apiManagementService := range page.Value {
 			resources, err := listAPIMangementBackends(ctx, backendClient, apiManagementService)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *apiManagementService.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, resource := range resources {
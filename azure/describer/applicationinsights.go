@@ -1,15 +1,15 @@
 package describer
 
 import (
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/applicationinsights/armapplicationinsights"
 	"github.com/opengovern/og-azure-describer/azure/model"
 	"golang.org/x/net/context"
 	"strings"
 )
 
-func ApplicationInsights(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armapplicationinsights.NewClientFactory(subscription, cred, nil)
+func ApplicationInsights(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armapplicationinsights.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
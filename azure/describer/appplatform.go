@@ -2,17 +2,17 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/springappdiscovery/armspringappdiscovery"
 	"strings"
 
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func SpringCloudService(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+func SpringCloudService(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
 	var values []Resource
 
-	clientFactory, err := armspringappdiscovery.NewClientFactory(subscription, cred, nil)
+	clientFactory, err := armspringappdiscovery.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -3,11 +3,10 @@ package describer
 import (
 	"context"
 	"fmt"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armpolicy"
 	"github.com/aws/aws-sdk-go-v2/aws"
-	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
 	"github.com/microsoftgraph/msgraph-sdk-go/groups"
 	"regexp"
 	"strings"
@@ -15,8 +14,8 @@ import (
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func RoleAssignment(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armauthorization.NewRoleAssignmentsClient(subscription, cred, nil)
+func RoleAssignment(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armauthorization.NewRoleAssignmentsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -54,8 +53,8 @@ func getRoleAssignment(ctx context.Context, v *armauthorization.RoleAssignment)
 	}
 }
 
-func RoleDefinition(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armauthorization.NewRoleDefinitionsClient(cred, nil)
+func RoleDefinition(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armauthorization.NewRoleDefinitionsClient(cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -94,8 +93,8 @@ func getRoleDefinition(ctx context.Context, v *armauthorization.RoleDefinition)
 	}
 }
 
-func PolicyDefinition(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armpolicy.NewClientFactory(subscription, cred, nil)
+func PolicyDefinition(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armpolicy.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -141,14 +140,13 @@ func getPolicyDefinition(ctx context.Context, subscription string, definition *a
 	}
 }
 
-func UserEffectiveAccess(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armauthorization.NewRoleAssignmentsClient(subscription, cred, nil)
+func UserEffectiveAccess(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armauthorization.NewRoleAssignmentsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	pager := client.NewListForSubscriptionPager(nil)
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	graphClient, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+	graphClient, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
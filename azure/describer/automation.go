@@ -2,15 +2,15 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/automation/armautomation"
 	"strings"
 
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func AutomationAccounts(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armautomation.NewClientFactory(subscription, cred, nil)
+func AutomationAccounts(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armautomation.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -56,8 +56,8 @@ func getAutomationAccount(ctx context.Context, account *armautomation.Account) *
 	return &resource
 }
 
-func AutomationVariables(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armautomation.NewClientFactory(subscription, cred, nil)
+func AutomationVariables(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armautomation.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -76,6 +76,9 @@ func AutomationVariables(ctx context.Context, cred *azidentity.ClientSecretCrede
 		for _, v := range result.Value {
 			resources, err := ListAutomationAccountVariables(ctx, variablesClient, v)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *v.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, resource := range resources {
@@ -2,7 +2,7 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/batch/armbatch"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
 	"strings"
@@ -10,14 +10,14 @@ import (
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func BatchAccount(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armbatch.NewClientFactory(subscription, cred, nil)
+func BatchAccount(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armbatch.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	client := clientFactory.NewAccountClient()
 
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -3,14 +3,14 @@ package describer
 import (
 	"context"
 	"fmt"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/blueprint/armblueprint"
 	"github.com/opengovern/og-azure-describer/azure/model"
 	"strings"
 )
 
-func BlueprintArtifact(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armblueprint.NewClientFactory(cred, nil)
+func BlueprintArtifact(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armblueprint.NewClientFactory(cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -60,8 +60,8 @@ func getBluePrintArtifact(ctx context.Context, v armblueprint.ArtifactClassifica
 	}
 }
 
-func BlueprintBlueprint(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armblueprint.NewClientFactory(cred, nil)
+func BlueprintBlueprint(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armblueprint.NewClientFactory(cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -2,15 +2,15 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cdn/armcdn"
 	"strings"
 
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func CdnProfiles(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcdn.NewClientFactory(subscription, cred, nil)
+func CdnProfiles(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcdn.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -54,8 +54,8 @@ func getCdnProfiles(ctx context.Context, v *armcdn.Profile) *Resource {
 	return &resource
 }
 
-func CdnEndpoint(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcdn.NewClientFactory(subscription, cred, nil)
+func CdnEndpoint(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcdn.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +73,9 @@ func CdnEndpoint(ctx context.Context, cred *azidentity.ClientSecretCredential, s
 		for _, v := range page.Value {
 			resources, err := getCdnProfilesEndpoints(ctx, endpointsClient, v)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *v.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, resource := range resources {
@@ -0,0 +1,27 @@
+package describer
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+var DefaultRetryOptions = policy.RetryOptions{
+	MaxRetries:    5,
+	RetryDelay:    4 * time.Second,
+	MaxRetryDelay: 2 * time.Minute,
+}
+
+// ClientOptions returns the options every ARM client of a describer is built with,
+// the requests go through the throttler of the context and target its cloud.
+func ClientOptions(ctx context.Context) *arm.ClientOptions {
+	return &arm.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			Cloud:            GetCloudFromContext(ctx).Configuration,
+			Retry:            DefaultRetryOptions,
+			PerRetryPolicies: []policy.Policy{GetThrottlerFromContext(ctx)},
+		},
+	}
+}
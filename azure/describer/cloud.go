@@ -0,0 +1,100 @@
+package describer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	az "github.com/microsoft/kiota-authentication-azure-go"
+	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
+)
+
+// Cloud is the set of endpoints of an Azure cloud the describers talk to.
+type Cloud struct {
+	// Name is the autorest environment name of the cloud, e.g. AzureUSGovernmentCloud.
+	Name string
+	// Configuration holds the authority host and the ARM endpoint and audience.
+	Configuration cloud.Configuration
+	// GraphEndpoint is the Microsoft Graph endpoint without trailing slash.
+	GraphEndpoint string
+	// StorageEndpointSuffix is the suffix of the storage account endpoints, e.g. core.windows.net.
+	StorageEndpointSuffix string
+}
+
+var (
+	PublicCloud = Cloud{
+		Name:                  "AzurePublicCloud",
+		Configuration:         cloud.AzurePublic,
+		GraphEndpoint:         "https://graph.microsoft.com",
+		StorageEndpointSuffix: "core.windows.net",
+	}
+	USGovernmentCloud = Cloud{
+		Name:                  "AzureUSGovernmentCloud",
+		Configuration:         cloud.AzureGovernment,
+		GraphEndpoint:         "https://graph.microsoft.us",
+		StorageEndpointSuffix: "core.usgovcloudapi.net",
+	}
+	ChinaCloud = Cloud{
+		Name:                  "AzureChinaCloud",
+		Configuration:         cloud.AzureChina,
+		GraphEndpoint:         "https://microsoftgraph.chinacloudapi.cn",
+		StorageEndpointSuffix: "core.chinacloudapi.cn",
+	}
+)
+
+// CloudFromName returns the cloud of an environment name. It accepts the autorest environment names
+// (AzurePublicCloud, AzureUSGovernmentCloud, AzureChinaCloud) and their short forms, an empty name is the public cloud.
+func CloudFromName(name string) (Cloud, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "azurepubliccloud", "azurecloud", "public":
+		return PublicCloud, nil
+	case "azureusgovernmentcloud", "azureusgovernment", "usgovernment", "usgov":
+		return USGovernmentCloud, nil
+	case "azurechinacloud", "china":
+		return ChinaCloud, nil
+	default:
+		return Cloud{}, fmt.Errorf("unknown azure cloud: %s", name)
+	}
+}
+
+// ResourceManagerEndpoint returns the ARM endpoint of the cloud without trailing slash.
+func (c Cloud) ResourceManagerEndpoint() string {
+	if svc, ok := c.Configuration.Services[cloud.ResourceManager]; ok {
+		return strings.TrimSuffix(svc.Endpoint, "/")
+	}
+	return ""
+}
+
+// GraphScope is the token scope of Microsoft Graph in the cloud.
+func (c Cloud) GraphScope() string {
+	return c.GraphEndpoint + "/.default"
+}
+
+// StorageEndpoint returns the endpoint of a storage account service, e.g. blob or table.
+func (c Cloud) StorageEndpoint(account, service string) string {
+	return fmt.Sprintf("https://%s.%s.%s/", account, service, c.StorageEndpointSuffix)
+}
+
+// NewGraphServiceClient builds a Microsoft Graph client against the Graph endpoint of the cloud of the context.
+func NewGraphServiceClient(ctx context.Context, cred azcore.TokenCredential) (*msgraphsdk.GraphServiceClient, error) {
+	c := GetCloudFromContext(ctx)
+	endpoint, err := url.Parse(c.GraphEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid graph endpoint %s: %w", c.GraphEndpoint, err)
+	}
+
+	auth, err := az.NewAzureIdentityAuthenticationProviderWithScopesAndValidHosts(cred, []string{c.GraphScope()}, []string{endpoint.Host})
+	if err != nil {
+		return nil, err
+	}
+	adapter, err := msgraphsdk.NewGraphRequestAdapter(auth)
+	if err != nil {
+		return nil, err
+	}
+	// the base url is captured by the client, it must be set before building it
+	adapter.SetBaseUrl(c.GraphEndpoint + "/v1.0")
+	return msgraphsdk.NewGraphServiceClient(adapter), nil
+}
@@ -6,7 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v4"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/guestconfiguration/armguestconfiguration"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
@@ -15,8 +16,8 @@ import (
 	"github.com/turbot/go-kit/types"
 )
 
-func ComputeDisk(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeDisk(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -59,8 +60,8 @@ func getComputeDisk(ctx context.Context, v *armcompute.Disk) *Resource {
 	}
 }
 
-func ComputeDiskAccess(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeDiskAccess(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -103,8 +104,8 @@ func getComputeDiskAccess(ctx context.Context, v *armcompute.DiskAccess) *Resour
 	}
 }
 
-func ComputeVirtualMachineScaleSet(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeVirtualMachineScaleSet(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -165,14 +166,14 @@ func getComputeVirtualMachineScaleSet(ctx context.Context, clientExtension *armc
 	return &resource, nil
 }
 
-func ComputeVirtualMachineScaleSetNetworkInterface(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeVirtualMachineScaleSetNetworkInterface(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	client := clientFactory.NewVirtualMachineScaleSetsClient()
 
-	networkClient, err := armnetwork.NewInterfacesClient(subscription, cred, nil)
+	networkClient, err := armnetwork.NewInterfacesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -190,6 +191,9 @@ func ComputeVirtualMachineScaleSetNetworkInterface(ctx context.Context, cred *az
 			for pager.More() {
 				page, err := pager.NextPage(ctx)
 				if err != nil {
+					if ReportScopeFailure(ctx, ScopeTypeResource, *vm.ID, err) {
+						break
+					}
 					return nil, err
 				}
 				for _, v := range page.Value {
@@ -230,8 +234,8 @@ func getComputeVirtualMachineScaleSetNetworkInterface(ctx context.Context, vm *a
 	return &resource
 }
 
-func ComputeVirtualMachineScaleSetVm(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeVirtualMachineScaleSetVm(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -251,6 +255,9 @@ func ComputeVirtualMachineScaleSetVm(ctx context.Context, cred *azidentity.Clien
 			for pager.More() {
 				page, err := pager.NextPage(ctx)
 				if err != nil {
+					if ReportScopeFailure(ctx, ScopeTypeResource, *vm.ID, err) {
+						break
+					}
 					return nil, err
 				}
 				for _, v := range page.Value {
@@ -301,57 +308,120 @@ func getStatusFromCode(statuses []*armcompute.InstanceViewStatus, codeType strin
 	return ""
 }
 
-func ComputeVirtualMachine(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeVirtualMachine(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	vmClient := clientFactory.NewVirtualMachinesClient()
 	vmExtensionsClient := clientFactory.NewVirtualMachineExtensionsClient()
 
-	networkInterfaceClient, err := armnetwork.NewInterfacesClient(subscription, cred, nil)
+	networkInterfaceClient, err := armnetwork.NewInterfacesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
-	networkPublicIPClient, err := armnetwork.NewPublicIPAddressesClient(subscription, cred, nil)
+	networkPublicIPClient, err := armnetwork.NewPublicIPAddressesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
-	ipConfigClient, err := armnetwork.NewInterfaceIPConfigurationsClient(subscription, cred, nil)
+	ipConfigClient, err := armnetwork.NewInterfaceIPConfigurationsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 
-	guestConfigurationClientFactory, err := armguestconfiguration.NewClientFactory(subscription, cred, nil)
+	guestConfigurationClientFactory, err := armguestconfiguration.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	guestConfigurationClient := guestConfigurationClientFactory.NewAssignmentsClient()
 
-	pager := vmClient.NewListAllPager(nil)
 	var values []Resource
-	for pager.More() {
-		page, err := pager.NextPage(ctx)
-		if err != nil {
-			return nil, err
-		}
-		for _, virtualMachine := range page.Value {
+	describe := func(virtualMachines []*armcompute.VirtualMachine) error {
+		for _, virtualMachine := range virtualMachines {
+			if !InScope(ctx, *virtualMachine.ID, virtualMachine.Tags) {
+				continue
+			}
 			resource, err := getComputeVirtualMachine(ctx, vmClient, vmExtensionsClient, networkInterfaceClient, networkPublicIPClient, ipConfigClient, guestConfigurationClient, virtualMachine)
 			if err != nil {
-				return nil, err
+				return err
 			}
 			if stream != nil {
 				if err := (*stream)(*resource); err != nil {
-					return nil, err
+					return err
 				}
 			} else {
 				values = append(values, *resource)
 			}
 		}
+		return nil
+	}
+
+	if resourceGroups := GetResourceGroupsFromContext(ctx); len(resourceGroups) > 0 {
+		for _, resourceGroup := range resourceGroups {
+			pager := vmClient.NewListPager(resourceGroup, nil)
+			for pager.More() {
+				page, err := pager.NextPage(ctx)
+				if err != nil {
+					if isResourceGroupNotFound(err) || ReportScopeFailure(ctx, ScopeTypeResourceGroup, resourceGroupID(subscription, resourceGroup), err) {
+						break
+					}
+					return nil, err
+				}
+				if err := describe(page.Value); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return values, nil
+	}
+
+	pager := vmClient.NewListAllPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := describe(page.Value); err != nil {
+			return nil, err
+		}
 	}
 	return values, nil
 }
 
+func ComputeVirtualMachineByID(ctx context.Context, cred azcore.TokenCredential, id *arm.ResourceID) (*Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(id.SubscriptionID, cred, ClientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+	vmClient := clientFactory.NewVirtualMachinesClient()
+	vmExtensionsClient := clientFactory.NewVirtualMachineExtensionsClient()
+
+	networkInterfaceClient, err := armnetwork.NewInterfacesClient(id.SubscriptionID, cred, ClientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+	networkPublicIPClient, err := armnetwork.NewPublicIPAddressesClient(id.SubscriptionID, cred, ClientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+	ipConfigClient, err := armnetwork.NewInterfaceIPConfigurationsClient(id.SubscriptionID, cred, ClientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	guestConfigurationClientFactory, err := armguestconfiguration.NewClientFactory(id.SubscriptionID, cred, ClientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+	guestConfigurationClient := guestConfigurationClientFactory.NewAssignmentsClient()
+
+	virtualMachine, err := vmClient.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return getComputeVirtualMachine(ctx, vmClient, vmExtensionsClient, networkInterfaceClient, networkPublicIPClient, ipConfigClient, guestConfigurationClient, &virtualMachine.VirtualMachine)
+}
+
 func getComputeVirtualMachine(ctx context.Context, vmClient *armcompute.VirtualMachinesClient, vmExtensionsClient *armcompute.VirtualMachineExtensionsClient, networkInterfaceClient *armnetwork.InterfacesClient, networkPublicIPClient *armnetwork.PublicIPAddressesClient, ipConfigClient *armnetwork.InterfaceIPConfigurationsClient, guestConfigurationClient *armguestconfiguration.AssignmentsClient, virtualMachine *armcompute.VirtualMachine) (*Resource, error) {
 	resourceGroupName := strings.Split(*virtualMachine.ID, "/")[4]
 	computeInstanceViewOp, err := vmClient.InstanceView(ctx, resourceGroupName, *virtualMachine.Name, nil)
@@ -457,8 +527,8 @@ func getComputeVirtualMachine(ctx context.Context, vmClient *armcompute.VirtualM
 	return &resource, nil
 }
 
-func ComputeSnapshots(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeSnapshots(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -502,8 +572,8 @@ func getComputeSnapshot(ctx context.Context, snapshot *armcompute.Snapshot) *Res
 	return &resource
 }
 
-func ComputeAvailabilitySet(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeAvailabilitySet(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -547,8 +617,8 @@ func getComputeAvailabilitySet(ctx context.Context, availabilitySet *armcompute.
 	return &resource
 }
 
-func ComputeDiskEncryptionSet(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeDiskEncryptionSet(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -592,8 +662,8 @@ func getComputeDiskEncryptionSet(ctx context.Context, diskEncryptionSet *armcomp
 	return &resource
 }
 
-func ComputeGallery(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeGallery(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -637,8 +707,8 @@ func getComputeGallery(ctx context.Context, gallery *armcompute.Gallery) *Resour
 	return &resource
 }
 
-func ComputeImage(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeImage(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -681,8 +751,8 @@ func getComputeImage(ctx context.Context, v *armcompute.Image) *Resource {
 	return &resource
 }
 
-func ComputeHostGroup(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeHostGroup(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -725,8 +795,8 @@ func getComputeHostGroup(ctx context.Context, v *armcompute.DedicatedHostGroup)
 	return &resource
 }
 
-func ComputeHost(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeHost(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -743,6 +813,9 @@ func ComputeHost(ctx context.Context, cred *azidentity.ClientSecretCredential, s
 		for _, v := range page.Value {
 			resources, err := getComputeHostsByGroup(ctx, hostClient, v)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *v.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, resource := range resources {
@@ -787,8 +860,8 @@ func getComputeHostsByGroup(ctx context.Context, hostClient *armcompute.Dedicate
 	return resources, nil
 }
 
-func ComputeRestorePointCollection(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeRestorePointCollection(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -831,8 +904,8 @@ func getComputeResourcePointCollection(ctx context.Context, v *armcompute.Restor
 	return &resource
 }
 
-func ComputeSSHPublicKey(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeSSHPublicKey(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -875,8 +948,8 @@ func getComputeSSHPublicKey(ctx context.Context, v *armcompute.SSHPublicKeyResou
 	return &resource
 }
 
-func ComputeDiskReadOps(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeDiskReadOps(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -915,7 +988,7 @@ func ComputeDiskReadOps(ctx context.Context, cred *azidentity.ClientSecretCreden
 	return values, nil
 }
 
-func getComputeDiskReadOps(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, disk *armcompute.Disk) ([]Resource, error) {
+func getComputeDiskReadOps(ctx context.Context, cred azcore.TokenCredential, subscription string, disk *armcompute.Disk) ([]Resource, error) {
 	metrics, err := listAzureMonitorMetricStatistics(ctx, cred, subscription, "FIVE_MINUTES", "Microsoft.Compute/disks", "Composite Disk Read Operations/sec", *disk.ID)
 	if err != nil {
 		return nil, err
@@ -937,8 +1010,8 @@ func getComputeDiskReadOps(ctx context.Context, cred *azidentity.ClientSecretCre
 	return values, nil
 }
 
-func ComputeDiskReadOpsDaily(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeDiskReadOpsDaily(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -977,7 +1050,7 @@ func ComputeDiskReadOpsDaily(ctx context.Context, cred *azidentity.ClientSecretC
 	return values, nil
 }
 
-func getComputeDiskReadOpsDaily(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, disk *armcompute.Disk) ([]Resource, error) {
+func getComputeDiskReadOpsDaily(ctx context.Context, cred azcore.TokenCredential, subscription string, disk *armcompute.Disk) ([]Resource, error) {
 	metrics, err := listAzureMonitorMetricStatistics(ctx, cred, subscription, "DAILY", "Microsoft.Compute/disks", "Composite Disk Read Operations/sec", *disk.ID)
 	if err != nil {
 		return nil, err
@@ -998,8 +1071,8 @@ func getComputeDiskReadOpsDaily(ctx context.Context, cred *azidentity.ClientSecr
 	}
 	return values, nil
 }
-func ComputeDiskReadOpsHourly(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeDiskReadOpsHourly(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1038,7 +1111,7 @@ func ComputeDiskReadOpsHourly(ctx context.Context, cred *azidentity.ClientSecret
 	return values, nil
 }
 
-func getComputeDiskReadOpsHourly(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, disk *armcompute.Disk) ([]Resource, error) {
+func getComputeDiskReadOpsHourly(ctx context.Context, cred azcore.TokenCredential, subscription string, disk *armcompute.Disk) ([]Resource, error) {
 	metrics, err := listAzureMonitorMetricStatistics(ctx, cred, subscription, "HOURLY", "Microsoft.Compute/disks", "Composite Disk Read Operations/sec", *disk.ID)
 	if err != nil {
 		return nil, err
@@ -1060,8 +1133,8 @@ func getComputeDiskReadOpsHourly(ctx context.Context, cred *azidentity.ClientSec
 	return values, nil
 }
 
-func ComputeDiskWriteOps(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeDiskWriteOps(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1100,7 +1173,7 @@ func ComputeDiskWriteOps(ctx context.Context, cred *azidentity.ClientSecretCrede
 	return values, nil
 }
 
-func getComputeDiskWriteOps(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, disk *armcompute.Disk) ([]Resource, error) {
+func getComputeDiskWriteOps(ctx context.Context, cred azcore.TokenCredential, subscription string, disk *armcompute.Disk) ([]Resource, error) {
 	metrics, err := listAzureMonitorMetricStatistics(ctx, cred, subscription, "FIVE_MINUTES", "Microsoft.Compute/disks", "Composite Disk Write Operations/sec", *disk.ID)
 	if err != nil {
 		return nil, err
@@ -1122,8 +1195,8 @@ func getComputeDiskWriteOps(ctx context.Context, cred *azidentity.ClientSecretCr
 	return values, nil
 }
 
-func ComputeDiskWriteOpsDaily(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeDiskWriteOpsDaily(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1162,7 +1235,7 @@ func ComputeDiskWriteOpsDaily(ctx context.Context, cred *azidentity.ClientSecret
 	return values, nil
 }
 
-func getComputeDiskWriteOpsDaily(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, disk *armcompute.Disk) ([]Resource, error) {
+func getComputeDiskWriteOpsDaily(ctx context.Context, cred azcore.TokenCredential, subscription string, disk *armcompute.Disk) ([]Resource, error) {
 	metrics, err := listAzureMonitorMetricStatistics(ctx, cred, subscription, "DAILY", "Microsoft.Compute/disks", "Composite Disk Write Operations/sec", *disk.ID)
 	if err != nil {
 		return nil, err
@@ -1183,8 +1256,8 @@ func getComputeDiskWriteOpsDaily(ctx context.Context, cred *azidentity.ClientSec
 	}
 	return values, nil
 }
-func ComputeDiskWriteOpsHourly(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeDiskWriteOpsHourly(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1223,7 +1296,7 @@ func ComputeDiskWriteOpsHourly(ctx context.Context, cred *azidentity.ClientSecre
 	return values, nil
 }
 
-func getComputeDiskWriteOpsHourly(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, disk *armcompute.Disk) ([]Resource, error) {
+func getComputeDiskWriteOpsHourly(ctx context.Context, cred azcore.TokenCredential, subscription string, disk *armcompute.Disk) ([]Resource, error) {
 	metrics, err := listAzureMonitorMetricStatistics(ctx, cred, subscription, "HOURLY", "Microsoft.Compute/disks", "Composite Disk Write Operations/sec", *disk.ID)
 	if err != nil {
 		return nil, err
@@ -1245,8 +1318,8 @@ func getComputeDiskWriteOpsHourly(ctx context.Context, cred *azidentity.ClientSe
 	return values, nil
 }
 
-func ComputeResourceSKU(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeResourceSKU(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1293,8 +1366,8 @@ func getComputeResourceSKU(ctx context.Context, subscription string, resourceSku
 	return &resource
 }
 
-func ComputeVirtualMachineCpuUtilization(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeVirtualMachineCpuUtilization(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1333,7 +1406,7 @@ func ComputeVirtualMachineCpuUtilization(ctx context.Context, cred *azidentity.C
 	return values, nil
 }
 
-func getComputeVirtualMachineCpuUtilization(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, virtualMachine *armcompute.VirtualMachine) ([]Resource, error) {
+func getComputeVirtualMachineCpuUtilization(ctx context.Context, cred azcore.TokenCredential, subscription string, virtualMachine *armcompute.VirtualMachine) ([]Resource, error) {
 	metrics, err := listAzureMonitorMetricStatistics(ctx, cred, subscription, "FIVE_MINUTES", "Microsoft.Compute/virtualMachines", "Percentage CPU", *virtualMachine.ID)
 	if err != nil {
 		return nil, err
@@ -1355,8 +1428,8 @@ func getComputeVirtualMachineCpuUtilization(ctx context.Context, cred *azidentit
 	return values, nil
 }
 
-func ComputeVirtualMachineCpuUtilizationDaily(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeVirtualMachineCpuUtilizationDaily(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1395,7 +1468,7 @@ func ComputeVirtualMachineCpuUtilizationDaily(ctx context.Context, cred *azident
 	return values, nil
 }
 
-func getComputeVirtualMachineCpuUtilizationDaily(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, virtualMachine *armcompute.VirtualMachine) ([]Resource, error) {
+func getComputeVirtualMachineCpuUtilizationDaily(ctx context.Context, cred azcore.TokenCredential, subscription string, virtualMachine *armcompute.VirtualMachine) ([]Resource, error) {
 	metrics, err := listAzureMonitorMetricStatistics(ctx, cred, subscription, "DAILY", "Microsoft.Compute/virtualMachines", "Percentage CPU", *virtualMachine.ID)
 	if err != nil {
 		return nil, err
@@ -1417,8 +1490,8 @@ func getComputeVirtualMachineCpuUtilizationDaily(ctx context.Context, cred *azid
 	return values, nil
 }
 
-func ComputeVirtualMachineCpuUtilizationHourly(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeVirtualMachineCpuUtilizationHourly(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1457,7 +1530,7 @@ func ComputeVirtualMachineCpuUtilizationHourly(ctx context.Context, cred *aziden
 	return values, nil
 }
 
-func getComputeVirtualMachineCpuUtilizationHourly(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, virtualMachine *armcompute.VirtualMachine) ([]Resource, error) {
+func getComputeVirtualMachineCpuUtilizationHourly(ctx context.Context, cred azcore.TokenCredential, subscription string, virtualMachine *armcompute.VirtualMachine) ([]Resource, error) {
 	metrics, err := listAzureMonitorMetricStatistics(ctx, cred, subscription, "HOURLY", "Microsoft.Compute/virtualMachines", "Percentage CPU", *virtualMachine.ID)
 	if err != nil {
 		return nil, err
@@ -1479,8 +1552,8 @@ func getComputeVirtualMachineCpuUtilizationHourly(ctx context.Context, cred *azi
 	return values, nil
 }
 
-func ComputeCloudServices(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcompute.NewClientFactory(subscription, cred, nil)
+func ComputeCloudServices(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcompute.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -2,15 +2,15 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerinstance/armcontainerinstance"
 	"strings"
 
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func ContainerInstanceContainerGroups(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armcontainerinstance.NewContainerGroupsClient(subscription, cred, nil)
+func ContainerInstanceContainerGroups(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armcontainerinstance.NewContainerGroupsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
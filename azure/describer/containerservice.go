@@ -2,7 +2,7 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v4"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
 	"strings"
@@ -10,8 +10,8 @@ import (
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func KubernetesCluster(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armcontainerservice.NewManagedClustersClient(subscription, cred, nil)
+func KubernetesCluster(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armcontainerservice.NewManagedClustersClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -54,13 +54,13 @@ func getKubernatesCluster(ctx context.Context, v *armcontainerservice.ManagedClu
 	return &resource
 }
 
-func KubernetesServiceVersion(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	subClient, err := armsubscriptions.NewClient(cred, nil)
+func KubernetesServiceVersion(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	subClient, err := armsubscriptions.NewClient(cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 
-	client, err := armcontainerservice.NewManagedClustersClient(subscription, cred, nil)
+	client, err := armcontainerservice.NewManagedClustersClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
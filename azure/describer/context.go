@@ -2,34 +2,179 @@ package describer
 
 import (
 	"context"
+	"errors"
+
 	"github.com/opengovern/og-util/pkg/describe/enums"
 	"go.uber.org/zap"
 )
 
-var (
-	triggerTypeKey string = "trigger_type"
+type contextKey string
+
+const (
+	describeOptionsKey    contextKey = "describe_options"
+	partialFailuresKey    contextKey = "partial_failures"
+	throttlerKey          contextKey = "throttler"
+	throttlingCountersKey contextKey = "throttling_counters"
+	deduplicatorKey       contextKey = "deduplicator"
 )
 
+// WithDescribeOptions stores the options of the describe call, the describers read them
+// through GetDescribeOptionsFromContext and the typed accessors below.
+func WithDescribeOptions(ctx context.Context, opts DescribeOptions) context.Context {
+	return context.WithValue(ctx, describeOptionsKey, opts)
+}
+
+func GetDescribeOptionsFromContext(ctx context.Context) DescribeOptions {
+	opts, _ := ctx.Value(describeOptionsKey).(DescribeOptions)
+	return opts
+}
+
 func WithTriggerType(ctx context.Context, tt enums.DescribeTriggerType) context.Context {
-	return context.WithValue(ctx, triggerTypeKey, tt)
+	opts := GetDescribeOptionsFromContext(ctx)
+	opts.TriggerType = tt
+	return WithDescribeOptions(ctx, opts)
 }
 
 func GetTriggerTypeFromContext(ctx context.Context) enums.DescribeTriggerType {
-	tt, ok := ctx.Value(triggerTypeKey).(enums.DescribeTriggerType)
-	if !ok {
-		return ""
-	}
-	return tt
+	return GetDescribeOptionsFromContext(ctx).TriggerType
 }
 
 func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
-	return context.WithValue(ctx, "logger", logger)
+	opts := GetDescribeOptionsFromContext(ctx)
+	opts.Logger = logger
+	return WithDescribeOptions(ctx, opts)
 }
 
 func GetLoggerFromContext(ctx context.Context) *zap.Logger {
-	logger, ok := ctx.Value("logger").(*zap.Logger)
-	if !ok {
+	logger := GetDescribeOptionsFromContext(ctx).Logger
+	if logger == nil {
 		return zap.NewNop()
 	}
 	return logger
 }
+
+const DefaultSubscriptionConcurrency = 4
+
+// SubscriptionFanOut controls how a describer spanning several subscriptions
+// walks them.
+type SubscriptionFanOut struct {
+	// Concurrency is the maximum number of subscriptions described at the same time.
+	Concurrency int
+	// ContinueOnFailure keeps describing the remaining subscriptions when one of them fails,
+	// the failures are returned together as a SubscriptionErrors.
+	ContinueOnFailure bool
+}
+
+func WithSubscriptionFanOut(ctx context.Context, fanOut SubscriptionFanOut) context.Context {
+	opts := GetDescribeOptionsFromContext(ctx)
+	opts.FanOut = fanOut
+	return WithDescribeOptions(ctx, opts)
+}
+
+func GetSubscriptionFanOutFromContext(ctx context.Context) SubscriptionFanOut {
+	fanOut := GetDescribeOptionsFromContext(ctx).FanOut
+	if fanOut.Concurrency < 1 {
+		fanOut.Concurrency = DefaultSubscriptionConcurrency
+	}
+	return fanOut
+}
+
+func WithCloud(ctx context.Context, c Cloud) context.Context {
+	opts := GetDescribeOptionsFromContext(ctx)
+	opts.Cloud = c
+	return WithDescribeOptions(ctx, opts)
+}
+
+// GetCloudFromContext returns the cloud of the context, PublicCloud if there is none.
+func GetCloudFromContext(ctx context.Context) Cloud {
+	c := GetDescribeOptionsFromContext(ctx).Cloud
+	if c.Name == "" {
+		return PublicCloud
+	}
+	return c
+}
+
+// GetTimeWindowFromContext returns the time window override of the context, nil if the describers
+// should use their default window.
+func GetTimeWindowFromContext(ctx context.Context) *TimeWindow {
+	return GetDescribeOptionsFromContext(ctx).TimeWindow
+}
+
+// GetMetricGranularityFromContext returns the metric granularity override of the context, def if there is none.
+func GetMetricGranularityFromContext(ctx context.Context, def MetricGranularity) MetricGranularity {
+	if g := GetDescribeOptionsFromContext(ctx).MetricGranularity; g != "" {
+		return g
+	}
+	return def
+}
+
+// GetResourceGroupsFromContext returns the resource groups the describe call is scoped to, nil if it is not.
+func GetResourceGroupsFromContext(ctx context.Context) []string {
+	return GetDescribeOptionsFromContext(ctx).ResourceGroups
+}
+
+// IncludeChildResources reports whether the describers should fetch the child resources
+// (diagnostic settings, encryption scopes, ...) nested in the descriptions.
+func IncludeChildResources(ctx context.Context) bool {
+	return !GetDescribeOptionsFromContext(ctx).ExcludeChildResources
+}
+
+// WithPartialFailures makes the describers skip the scopes they fail to describe and record them in failures
+// instead of failing the whole describe call.
+func WithPartialFailures(ctx context.Context, failures *PartialFailures) context.Context {
+	return context.WithValue(ctx, partialFailuresKey, failures)
+}
+
+// ReportScopeFailure records a failed scope if the context tolerates partial results.
+// It returns false if it does not, or if the describe call was cancelled or timed out, in which case the
+// describer should return the error.
+func ReportScopeFailure(ctx context.Context, scopeType ScopeType, id string, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	failures, ok := ctx.Value(partialFailuresKey).(*PartialFailures)
+	if !ok || failures == nil {
+		return false
+	}
+	GetLoggerFromContext(ctx).Warn("skipping failed scope", zap.String("scopeType", string(scopeType)), zap.String("scope", id), zap.Error(err))
+	failures.Add(scopeType, id, err)
+	return true
+}
+
+func WithThrottler(ctx context.Context, throttler *Throttler) context.Context {
+	return context.WithValue(ctx, throttlerKey, throttler)
+}
+
+// GetThrottlerFromContext returns the throttler of the context, DefaultThrottler if there is none.
+func GetThrottlerFromContext(ctx context.Context) *Throttler {
+	throttler, ok := ctx.Value(throttlerKey).(*Throttler)
+	if !ok || throttler == nil {
+		return DefaultThrottler
+	}
+	return throttler
+}
+
+// WithThrottlingCounters makes the throttler count the requests of the describe calls of the context in
+// counters too, e.g. to report the throttling of a job while the throttler is shared by the concurrent jobs.
+func WithThrottlingCounters(ctx context.Context, counters *ThrottlingCounters) context.Context {
+	return context.WithValue(ctx, throttlingCountersKey, counters)
+}
+
+func getThrottlingCountersFromContext(ctx context.Context) *ThrottlingCounters {
+	counters, _ := ctx.Value(throttlingCountersKey).(*ThrottlingCounters)
+	return counters
+}
+
+// WithDeduplicator makes the describe calls of the context share the deduplicator, e.g. to read its counters.
+func WithDeduplicator(ctx context.Context, dedup *Deduplicator) context.Context {
+	return context.WithValue(ctx, deduplicatorKey, dedup)
+}
+
+// GetDeduplicatorFromContext returns the deduplicator of the context, a new one if there is none.
+func GetDeduplicatorFromContext(ctx context.Context) *Deduplicator {
+	dedup, ok := ctx.Value(deduplicatorKey).(*Deduplicator)
+	if !ok || dedup == nil {
+		return NewDeduplicator()
+	}
+	return dedup
+}
@@ -0,0 +1,38 @@
+package describer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestReportScopeFailure(t *testing.T) {
+	failures := &PartialFailures{}
+	ctx := WithPartialFailures(context.Background(), failures)
+
+	if !ReportScopeFailure(ctx, ScopeTypeResource, "vault", errors.New("forbidden")) {
+		t.Error("expected the failure to be recorded")
+	}
+	if !ReportScopeFailure(ctx, ScopeTypeResourceGroup, resourceGroupID("s", "rg"), errors.New("forbidden")) {
+		t.Error("expected the resource group failure to be recorded")
+	}
+	if ReportScopeFailure(ctx, ScopeTypeResource, "vault", fmt.Errorf("list secrets: %w", context.DeadlineExceeded)) {
+		t.Error("expected a timed out scope to fail the describe call")
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if ReportScopeFailure(cancelled, ScopeTypeResource, "vault", errors.New("forbidden")) {
+		t.Error("expected a cancelled describe call to return the error")
+	}
+	if ReportScopeFailure(context.Background(), ScopeTypeResource, "vault", errors.New("forbidden")) {
+		t.Error("expected the failure not to be recorded without partial failures")
+	}
+	scopes := failures.Scopes()
+	if len(scopes) != 2 {
+		t.Fatalf("got %d failed scopes, want 2", len(scopes))
+	}
+	if scopes[1].Type != ScopeTypeResourceGroup || scopes[1].ID != "/subscriptions/s/resourceGroups/rg" {
+		t.Errorf("unexpected failed scope %+v", scopes[1])
+	}
+}
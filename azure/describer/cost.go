@@ -7,7 +7,7 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
 
 	"github.com/opengovern/og-util/pkg/describe/enums"
@@ -19,9 +19,9 @@ const serviceNameDimension = "ServiceName"
 const publisherTypeDimension = "PublisherType"
 const subscriptionDimension = "SubscriptionId"
 
-func cost(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, from time.Time, to time.Time, dimension string) ([]model.CostManagementQueryRow, *string, error) {
+func cost(ctx context.Context, cred azcore.TokenCredential, subscription string, from time.Time, to time.Time, dimension string) ([]model.CostManagementQueryRow, *string, error) {
 	var err error
-	clientFactory, err := armcostmanagement.NewClientFactory(cred, nil)
+	clientFactory, err := armcostmanagement.NewClientFactory(cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -95,7 +95,7 @@ func cost(ctx context.Context, cred *azidentity.ClientSecretCredential, subscrip
 	return result, costs.Location, nil
 }
 
-func DailyCostByResourceType(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+func DailyCostByResourceType(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
 	triggerType := GetTriggerTypeFromContext(ctx)
 	from := time.Now().AddDate(0, 0, -7)
 	if time.Now().Day() == 6 {
@@ -108,6 +108,9 @@ func DailyCostByResourceType(ctx context.Context, cred *azidentity.ClientSecretC
 		from = time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC)
 	}
 	to := time.Now()
+	if window := GetTimeWindowFromContext(ctx); window != nil {
+		from, to = window.From, window.To
+	}
 
 	var costResult []model.CostManagementQueryRow
 	var locationPtr *string
@@ -172,13 +175,16 @@ func DailyCostByResourceType(ctx context.Context, cred *azidentity.ClientSecretC
 	return values, nil
 }
 
-func DailyCostBySubscription(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+func DailyCostBySubscription(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
 	triggerType := GetTriggerTypeFromContext(ctx)
 	from := time.Now().AddDate(0, 0, -7)
 	if triggerType == enums.DescribeTriggerTypeInitialDiscovery {
 		from = time.Now().AddDate(0, -3, -7)
 	}
 	to := time.Now()
+	if window := GetTimeWindowFromContext(ctx); window != nil {
+		from, to = window.From, window.To
+	}
 
 	costResult, locationPtr, err := cost(ctx, cred, subscription, from, to, subscriptionDimension)
 	if err != nil {
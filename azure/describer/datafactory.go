@@ -2,19 +2,19 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/datafactory/armdatafactory/v2"
 	"strings"
 
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func DataFactory(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armdatafactory.NewFactoriesClient(subscription, cred, nil)
+func DataFactory(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armdatafactory.NewFactoriesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
-	connClient, err := armdatafactory.NewPrivateEndPointConnectionsClient(subscription, cred, nil)
+	connClient, err := armdatafactory.NewPrivateEndPointConnectionsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -72,12 +72,12 @@ func getDataFactory(ctx context.Context, connClient *armdatafactory.PrivateEndPo
 	return &resource, nil
 }
 
-func DataFactoryDataset(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armdatafactory.NewFactoriesClient(subscription, cred, nil)
+func DataFactoryDataset(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armdatafactory.NewFactoriesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
-	datasetsClient, err := armdatafactory.NewDatasetsClient(subscription, cred, nil)
+	datasetsClient, err := armdatafactory.NewDatasetsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -140,12 +140,12 @@ func getDataFactoryDataset(ctx context.Context, client *armdatafactory.DatasetsC
 	return values, nil
 }
 
-func DataFactoryPipeline(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armdatafactory.NewFactoriesClient(subscription, cred, nil)
+func DataFactoryPipeline(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armdatafactory.NewFactoriesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
-	pipelineClient, err := armdatafactory.NewPipelinesClient(subscription, cred, nil)
+	pipelineClient, err := armdatafactory.NewPipelinesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -4,14 +4,14 @@ import (
 	"context"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/dataprotection/armdataprotection"
 
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func DataProtectionBackupVaults(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armdataprotection.NewBackupVaultsClient(subscription, cred, nil)
+func DataProtectionBackupVaults(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armdataprotection.NewBackupVaultsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -53,12 +53,12 @@ func getDataProtectionBackupVaults(ctx context.Context, v *armdataprotection.Bac
 	return &resource
 }
 
-func DataProtectionBackupVaultsBackupPolicies(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armdataprotection.NewBackupVaultsClient(subscription, cred, nil)
+func DataProtectionBackupVaultsBackupPolicies(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armdataprotection.NewBackupVaultsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
-	policiesClient, err := armdataprotection.NewBackupPoliciesClient(subscription, cred, nil)
+	policiesClient, err := armdataprotection.NewBackupPoliciesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -72,6 +72,9 @@ func DataProtectionBackupVaultsBackupPolicies(ctx context.Context, cred *azident
 		for _, v := range page.Value {
 			resources, err := getDataProtectionBackupVaultsBackupPolicies(ctx, policiesClient, v)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *v.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, resource := range resources {
@@ -118,14 +121,14 @@ func getDataProtectionBackupVaultsBackupPolicies(ctx context.Context, client *ar
 	return values, nil
 }
 
-func DataProtectionBackupJobs(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+func DataProtectionBackupJobs(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
 
-	client, err := armdataprotection.NewBackupVaultsClient(subscription, cred, nil)
+	client, err := armdataprotection.NewBackupVaultsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 
-	jobsClient, err := armdataprotection.NewJobsClient(subscription, cred, nil)
+	jobsClient, err := armdataprotection.NewJobsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -140,6 +143,9 @@ func DataProtectionBackupJobs(ctx context.Context, cred *azidentity.ClientSecret
 		for _, vault := range page.Value {
 			jobs, err := listDataProtectionBackupJobs(ctx, jobsClient, vault)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *vault.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, job := range jobs {
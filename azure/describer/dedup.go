@@ -0,0 +1,77 @@
+package describer
+
+import (
+	"crypto/sha256"
+	"sync"
+	"sync/atomic"
+)
+
+// Deduplicator drops the resources of a describe job whose UniqueID was already seen, e.g. the resources
+// listed twice by overlapping pages or nested listings. It is shared by the subscriptions of the job.
+type Deduplicator struct {
+	mu sync.Mutex
+	// seen holds digests rather than the IDs to bound the memory of the large tenants
+	seen map[[16]byte]struct{}
+
+	dropped atomic.Int64
+}
+
+// duplicateResources counts the duplicates dropped by all the deduplicators of the process.
+var duplicateResources atomic.Int64
+
+// DuplicateResources returns the number of duplicate resources dropped by the process, e.g. to export it as a
+// metric. The duplicates of a describe job are counted by the Dropped of its deduplicator.
+func DuplicateResources() int64 {
+	return duplicateResources.Load()
+}
+
+func NewDeduplicator() *Deduplicator {
+	return &Deduplicator{seen: map[[16]byte]struct{}{}}
+}
+
+// Add records the resource and reports whether it was not seen before.
+func (d *Deduplicator) Add(resource Resource) bool {
+	sum := sha256.Sum256([]byte(resource.UniqueID()))
+	var key [16]byte
+	copy(key[:], sum[:16])
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.seen[key]; ok {
+		d.dropped.Add(1)
+		duplicateResources.Add(1)
+		return false
+	}
+	d.seen[key] = struct{}{}
+	return true
+}
+
+// Stream wraps stream to drop the duplicate resources.
+func (d *Deduplicator) Stream(stream *StreamSender) *StreamSender {
+	if stream == nil {
+		return nil
+	}
+	f := StreamSender(func(resource Resource) error {
+		if !d.Add(resource) {
+			return nil
+		}
+		return (*stream)(resource)
+	})
+	return &f
+}
+
+// Filter drops the duplicate resources of values.
+func (d *Deduplicator) Filter(values []Resource) []Resource {
+	var kept []Resource
+	for _, resource := range values {
+		if d.Add(resource) {
+			kept = append(kept, resource)
+		}
+	}
+	return kept
+}
+
+// Dropped returns the number of duplicate resources dropped.
+func (d *Deduplicator) Dropped() int64 {
+	return d.dropped.Load()
+}
@@ -0,0 +1,65 @@
+package describer
+
+import (
+	"testing"
+
+	"github.com/opengovern/og-azure-describer/azure/model"
+)
+
+func TestResourceUniqueID(t *testing.T) {
+	for _, tc := range []struct {
+		resource Resource
+		want     string
+	}{
+		{
+			resource: Resource{ID: "/subscriptions/S/resourceGroups/RG/providers/Microsoft.Compute/virtualMachines/VM"},
+			want:     "/subscriptions/s/resourcegroups/rg/providers/microsoft.compute/virtualmachines/vm",
+		},
+		{
+			resource: Resource{ID: "0f7C|/providers/Microsoft.Authorization/roleAssignments/AB"},
+			want:     "0f7C|/providers/microsoft.authorization/roleassignments/ab",
+		},
+		{
+			resource: Resource{
+				ID: "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/disks/d_readops",
+				Description: JSONAllFieldsMarshaller{Value: model.ComputeDiskReadOpsDescription{
+					MonitoringMetric: model.MonitoringMetric{TimeStamp: "2024-01-01T00:00:00Z"},
+				}},
+			},
+			want: "/subscriptions/s/resourcegroups/rg/providers/microsoft.compute/disks/d_readops@2024-01-01T00:00:00Z",
+		},
+		{
+			resource: Resource{ID: "resource-cost-Microsoft.Compute/2024-01-01"},
+			want:     "resource-cost-Microsoft.Compute/2024-01-01",
+		},
+	} {
+		if got := tc.resource.UniqueID(); got != tc.want {
+			t.Errorf("got %s, want %s", got, tc.want)
+		}
+	}
+}
+
+func TestDeduplicator(t *testing.T) {
+	total := DuplicateResources()
+	dedup := NewDeduplicator()
+	var streamed []Resource
+	stream := StreamSender(func(resource Resource) error {
+		streamed = append(streamed, resource)
+		return nil
+	})
+	deduped := dedup.Stream(&stream)
+	for _, id := range []string{"/subscriptions/s/a", "/Subscriptions/S/A", "/subscriptions/s/b"} {
+		if err := (*deduped)(Resource{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(streamed) != 2 || dedup.Dropped() != 1 {
+		t.Errorf("expected 2 resources and 1 duplicate, got %d and %d", len(streamed), dedup.Dropped())
+	}
+	if values := dedup.Filter([]Resource{{ID: "/subscriptions/s/b"}, {ID: "/subscriptions/s/c"}}); len(values) != 1 {
+		t.Errorf("expected 1 resource, got %d", len(values))
+	}
+	if n := DuplicateResources() - total; n != 2 {
+		t.Errorf("expected 2 duplicates counted for the process, got %d", n)
+	}
+}
@@ -2,15 +2,15 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/desktopvirtualization/armdesktopvirtualization"
 	"strings"
 
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func DesktopVirtualizationWorkspaces(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armdesktopvirtualization.NewWorkspacesClient(subscription, cred, nil)
+func DesktopVirtualizationWorkspaces(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armdesktopvirtualization.NewWorkspacesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -46,8 +46,8 @@ func DesktopVirtualizationWorkspaces(ctx context.Context, cred *azidentity.Clien
 	return values, nil
 }
 
-func DesktopVirtualizationHostPool(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armdesktopvirtualization.NewHostPoolsClient(subscription, cred, nil)
+func DesktopVirtualizationHostPool(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armdesktopvirtualization.NewHostPoolsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
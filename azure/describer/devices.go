@@ -2,7 +2,7 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/deviceprovisioningservices/armdeviceprovisioningservices"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/iothub/armiothub"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
@@ -12,8 +12,8 @@ import (
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func DevicesProvisioningServicesCertificates(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armdeviceprovisioningservices.NewDpsCertificateClient(subscription, cred, nil)
+func DevicesProvisioningServicesCertificates(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armdeviceprovisioningservices.NewDpsCertificateClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -70,8 +70,8 @@ func getDevicesProvisioningServicesCertificates(ctx context.Context, client *arm
 	return values, nil
 }
 
-func devicesProvisioningServices(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, resourceGroup string) ([]armdeviceprovisioningservices.ProvisioningServiceDescription, error) {
-	clientFactory, err := armdeviceprovisioningservices.NewClientFactory(subscription, cred, nil)
+func devicesProvisioningServices(ctx context.Context, cred azcore.TokenCredential, subscription string, resourceGroup string) ([]armdeviceprovisioningservices.ProvisioningServiceDescription, error) {
+	clientFactory, err := armdeviceprovisioningservices.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -92,14 +92,14 @@ func devicesProvisioningServices(ctx context.Context, cred *azidentity.ClientSec
 	return values, nil
 }
 
-func IOTHub(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+func IOTHub(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	diagnosticClient := monitorClientFactory.NewDiagnosticSettingsClient()
 
-	iotHubClient, err := armiothub.NewResourceClient(subscription, cred, nil)
+	iotHubClient, err := armiothub.NewResourceClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -161,14 +161,14 @@ func getIOTHub(ctx context.Context, client *armmonitor.DiagnosticSettingsClient,
 	return &resource, nil
 }
 
-func IOTHubDps(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+func IOTHubDps(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	diagnosticClient := monitorClientFactory.NewDiagnosticSettingsClient()
 
-	clientFactory, err := armdeviceprovisioningservices.NewClientFactory(subscription, cred, nil)
+	clientFactory, err := armdeviceprovisioningservices.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
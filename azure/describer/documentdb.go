@@ -2,7 +2,7 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cosmos/armcosmos/v2"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"strings"
@@ -10,13 +10,13 @@ import (
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func DocumentDBSQLDatabase(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+func DocumentDBSQLDatabase(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
 	rgs, err := listResourceGroups(ctx, cred, subscription)
 	if err != nil {
 		return nil, err
 	}
 
-	clientFactory, err := armcosmos.NewClientFactory(subscription, cred, nil)
+	clientFactory, err := armcosmos.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -79,13 +79,13 @@ func getDocumentDBSQLDatabase(ctx context.Context, v *armcosmos.SQLDatabaseGetRe
 	return &resource
 }
 
-func DocumentDBMongoDatabase(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+func DocumentDBMongoDatabase(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
 	rgs, err := listResourceGroups(ctx, cred, subscription)
 	if err != nil {
 		return nil, err
 	}
 
-	clientFactory, err := armcosmos.NewClientFactory(subscription, cred, nil)
+	clientFactory, err := armcosmos.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -146,13 +146,13 @@ func getDocumentDBMongoDatabase(ctx context.Context, v *armcosmos.MongoDBDatabas
 	return &resource
 }
 
-func DocumentDBMongoCollection(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+func DocumentDBMongoCollection(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
 	rgs, err := listResourceGroups(ctx, cred, subscription)
 	if err != nil {
 		return nil, err
 	}
 
-	clientFactory, err := armcosmos.NewClientFactory(subscription, cred, nil)
+	clientFactory, err := armcosmos.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -243,8 +243,8 @@ func getDocumentDBMongoCollection(ctx context.Context, client *armcosmos.MongoDB
 	return &resource, nil
 }
 
-func DocumentDBCassandraCluster(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcosmos.NewClientFactory(subscription, cred, nil)
+func DocumentDBCassandraCluster(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcosmos.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -291,8 +291,8 @@ func getDocumentDBCassandraCluster(ctx context.Context, v *armcosmos.ClusterReso
 	return &resource
 }
 
-func documentDBDatabaseAccounts(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, resourceGroup string) ([]*armcosmos.DatabaseAccountGetResults, error) {
-	clientFactory, err := armcosmos.NewClientFactory(subscription, cred, nil)
+func documentDBDatabaseAccounts(ctx context.Context, cred azcore.TokenCredential, subscription string, resourceGroup string) ([]*armcosmos.DatabaseAccountGetResults, error) {
+	clientFactory, err := armcosmos.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -311,8 +311,8 @@ func documentDBDatabaseAccounts(ctx context.Context, cred *azidentity.ClientSecr
 	return values, nil
 }
 
-func CosmosdbAccount(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcosmos.NewClientFactory(subscription, cred, nil)
+func CosmosdbAccount(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcosmos.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -359,8 +359,8 @@ func getCosmosdbAccount(ctx context.Context, v *armcosmos.DatabaseAccountGetResu
 	return &resource
 }
 
-func CosmosdbRestorableDatabaseAccount(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armcosmos.NewClientFactory(subscription, cred, nil)
+func CosmosdbRestorableDatabaseAccount(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armcosmos.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
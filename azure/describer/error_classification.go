@@ -0,0 +1,265 @@
+package describer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+)
+
+// ErrorCategory is the stable classification of an error returned by Azure.
+type ErrorCategory string
+
+const (
+	ErrorCategoryAuthFailed            ErrorCategory = "AuthFailed"
+	ErrorCategoryForbidden             ErrorCategory = "Forbidden"
+	ErrorCategoryThrottled             ErrorCategory = "Throttled"
+	ErrorCategoryNotFound              ErrorCategory = "NotFound"
+	ErrorCategoryProviderNotRegistered ErrorCategory = "ProviderNotRegistered"
+	ErrorCategoryFeatureNotEnabled     ErrorCategory = "FeatureNotEnabled"
+	ErrorCategoryTransient             ErrorCategory = "Transient"
+	ErrorCategoryInternal              ErrorCategory = "Internal"
+)
+
+// Retryable reports whether retrying the job later may succeed.
+func (c ErrorCategory) Retryable() bool {
+	return c == ErrorCategoryThrottled || c == ErrorCategoryTransient
+}
+
+// ClassifiedError is the result of ClassifyError.
+type ClassifiedError struct {
+	Category ErrorCategory
+	// Code is the error code returned by Azure, e.g. AuthorizationFailed.
+	Code    string
+	Message string
+	// StatusCode is the HTTP status code of the failed request, 0 if there was no response.
+	StatusCode int
+	// RequestID is the Azure request ID of the failed request, if available.
+	RequestID string
+}
+
+var (
+	authFailedCodes = []string{
+		"InvalidAuthenticationToken", "InvalidAuthenticationTokenTenant", "ExpiredAuthenticationToken",
+		"AuthenticationFailed", "InvalidAuthenticationInfo", "Authentication_ExpiredToken", "InvalidAuthenticationTokenAudience",
+	}
+	forbiddenCodes = []string{
+		"AuthorizationFailed", "LinkedAuthorizationFailed", "Authorization_RequestDenied", "Forbidden",
+		"AuthorizationPermissionMismatch", "ReadOnlyDisabledSubscription", "ScopeLocked",
+	}
+	throttledCodes = []string{
+		"TooManyRequests", "ResourceRequestsThrottled", "SubscriptionRequestsThrottled", "RequestThrottled",
+		"ResourceCollectionRequestsThrottled", "activityLimitReached",
+	}
+	notFoundCodes = []string{
+		"ResourceNotFound", "ResourceGroupNotFound", "SubscriptionNotFound", "NotFound", "ParentResourceNotFound",
+		"Request_ResourceNotFound", "ResourceNotFoundError",
+	}
+	providerNotRegisteredCodes = []string{
+		"MissingSubscriptionRegistration", "NoRegisteredProviderFound", "SubscriptionNotRegistered",
+	}
+	featureNotEnabledCodes = []string{
+		"FeatureNotEnabled", "FeatureNotSupported", "FeatureNotSupportedForAccount", "FeatureNotSupportedForSubscription",
+		"SubscriptionNotSupported", "BadRequest_FeatureNotEnabled",
+	}
+	transientCodes = []string{
+		"InternalServerError", "ServiceUnavailable", "GatewayTimeout", "RequestTimeout", "OperationTimedOut",
+		"ServerTimeout", "InternalError", "generalException", "serviceNotAvailable",
+	}
+)
+
+// ClassifyError unwraps the typed errors of the Azure SDKs (azcore, autorest, msgraph and azidentity) and
+// classifies them. Errors of unknown types fall back to the JSON error body embedded in their message.
+func ClassifyError(err error) ClassifiedError {
+	if err == nil {
+		return ClassifiedError{}
+	}
+
+	c := ClassifiedError{Message: err.Error()}
+
+	var authErr *azidentity.AuthenticationFailedError
+	var responseErr *azcore.ResponseError
+	var requestErr azure.RequestError
+	var detailedErr autorest.DetailedError
+	var odataErr *odataerrors.ODataError
+	switch {
+	case errors.As(err, &authErr):
+		c.Category = ErrorCategoryAuthFailed
+		c.Code = "AuthenticationFailed"
+		if authErr.RawResponse != nil {
+			c.StatusCode = authErr.RawResponse.StatusCode
+			c.RequestID = requestIDFromHeader(authErr.RawResponse.Header)
+		}
+		return c
+	case errors.As(err, &responseErr):
+		c.Code = responseErr.ErrorCode
+		c.StatusCode = responseErr.StatusCode
+		if responseErr.RawResponse != nil {
+			c.RequestID = requestIDFromHeader(responseErr.RawResponse.Header)
+		}
+	case asRequestError(err, &requestErr):
+		if requestErr.ServiceError != nil {
+			c.Code = requestErr.ServiceError.Code
+			c.Message = requestErr.ServiceError.Message
+		}
+		c.StatusCode = statusCodeOf(requestErr.DetailedError)
+		c.RequestID = requestErr.RequestID
+	case asDetailedError(err, &detailedErr):
+		c.StatusCode = statusCodeOf(detailedErr)
+		if detailedErr.Response != nil {
+			c.RequestID = requestIDFromHeader(detailedErr.Response.Header)
+		}
+		if len(detailedErr.ServiceError) > 0 {
+			c.Code, c.Message = errorCodeAndMessageFromJSON(string(detailedErr.ServiceError))
+		}
+	case errors.As(err, &odataErr):
+		c.StatusCode = odataErr.ResponseStatusCode
+		if mainErr := odataErr.GetErrorEscaped(); mainErr != nil {
+			if code := mainErr.GetCode(); code != nil {
+				c.Code = *code
+			}
+			if msg := mainErr.GetMessage(); msg != nil {
+				c.Message = *msg
+			}
+			if innerErr := mainErr.GetInnerError(); innerErr != nil && innerErr.GetRequestId() != nil {
+				c.RequestID = *innerErr.GetRequestId()
+			}
+		}
+	}
+
+	if c.Code == "" {
+		code, msg := errorCodeAndMessageFromJSON(err.Error())
+		if code != "" {
+			c.Code, c.Message = code, msg
+		}
+	}
+
+	c.Category = categoryOf(err, c.Code, c.StatusCode)
+	return c
+}
+
+func categoryOf(err error, code string, statusCode int) ErrorCategory {
+	switch {
+	case containsCode(authFailedCodes, code):
+		return ErrorCategoryAuthFailed
+	case containsCode(providerNotRegisteredCodes, code):
+		return ErrorCategoryProviderNotRegistered
+	case containsCode(featureNotEnabledCodes, code):
+		return ErrorCategoryFeatureNotEnabled
+	case containsCode(forbiddenCodes, code):
+		return ErrorCategoryForbidden
+	case containsCode(throttledCodes, code):
+		return ErrorCategoryThrottled
+	case containsCode(notFoundCodes, code):
+		return ErrorCategoryNotFound
+	case containsCode(transientCodes, code):
+		return ErrorCategoryTransient
+	}
+
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		return ErrorCategoryAuthFailed
+	case statusCode == http.StatusForbidden:
+		return ErrorCategoryForbidden
+	case statusCode == http.StatusTooManyRequests:
+		return ErrorCategoryThrottled
+	case statusCode == http.StatusNotFound:
+		return ErrorCategoryNotFound
+	case statusCode == http.StatusRequestTimeout || statusCode >= http.StatusInternalServerError:
+		return ErrorCategoryTransient
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorCategoryTransient
+	}
+
+	return ErrorCategoryInternal
+}
+
+func containsCode(codes []string, code string) bool {
+	if code == "" {
+		return false
+	}
+	for _, c := range codes {
+		if strings.EqualFold(c, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// asRequestError is errors.As for azure.RequestError, which autorest returns either by value or by pointer.
+func asRequestError(err error, target *azure.RequestError) bool {
+	var ptr *azure.RequestError
+	if errors.As(err, &ptr) {
+		*target = *ptr
+		return true
+	}
+	return errors.As(err, target)
+}
+
+// asDetailedError is errors.As for autorest.DetailedError, which autorest returns either by value or by pointer.
+func asDetailedError(err error, target *autorest.DetailedError) bool {
+	var ptr *autorest.DetailedError
+	if errors.As(err, &ptr) {
+		*target = *ptr
+		return true
+	}
+	return errors.As(err, target)
+}
+
+func statusCodeOf(detailedErr autorest.DetailedError) int {
+	if statusCode, ok := detailedErr.StatusCode.(int); ok {
+		return statusCode
+	}
+	if detailedErr.Response != nil {
+		return detailedErr.Response.StatusCode
+	}
+	return 0
+}
+
+func requestIDFromHeader(header http.Header) string {
+	for _, key := range []string{"x-ms-request-id", "x-ms-correlation-request-id", "request-id", "client-request-id"} {
+		if v := header.Get(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// errorCodeAndMessageFromJSON extracts the code and message of the {"error": {"code": ..., "message": ...}}
+// body embedded in s. It returns an empty code if there is none.
+func errorCodeAndMessageFromJSON(s string) (string, string) {
+	jsonStart := strings.Index(s, "{")
+	jsonEnd := strings.LastIndex(s, "}")
+	if jsonStart < 0 || jsonEnd < jsonStart {
+		return "", s
+	}
+
+	var jsonData map[string]interface{}
+	if err := json.Unmarshal([]byte(s[jsonStart:jsonEnd+1]), &jsonData); err != nil || jsonData == nil {
+		return "", s
+	}
+
+	errorData, ok := jsonData["error"].(map[string]interface{})
+	if !ok {
+		return "", s
+	}
+
+	code, _ := errorData["code"].(string)
+	msg, ok := errorData["message"].(string)
+	if !ok {
+		msg = fmt.Sprintf("ErrMsg= %v", errorData)
+	}
+	return code, msg
+}
@@ -0,0 +1,114 @@
+package describer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+)
+
+func TestClassifyError(t *testing.T) {
+	header := http.Header{}
+	header.Set("x-ms-request-id", "request-1")
+
+	tests := []struct {
+		name       string
+		err        error
+		category   ErrorCategory
+		code       string
+		statusCode int
+		requestID  string
+	}{
+		{
+			name: "ResponseError/Forbidden",
+			err: fmt.Errorf("list vaults: %w", &azcore.ResponseError{
+				ErrorCode:   "AuthorizationFailed",
+				StatusCode:  http.StatusForbidden,
+				RawResponse: &http.Response{StatusCode: http.StatusForbidden, Header: header},
+			}),
+			category:   ErrorCategoryForbidden,
+			code:       "AuthorizationFailed",
+			statusCode: http.StatusForbidden,
+			requestID:  "request-1",
+		},
+		{
+			name: "ResponseError/ProviderNotRegistered",
+			err: &azcore.ResponseError{
+				ErrorCode:  "MissingSubscriptionRegistration",
+				StatusCode: http.StatusConflict,
+			},
+			category:   ErrorCategoryProviderNotRegistered,
+			code:       "MissingSubscriptionRegistration",
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "ResponseError/Throttled",
+			err: &azcore.ResponseError{
+				StatusCode: http.StatusTooManyRequests,
+			},
+			category:   ErrorCategoryThrottled,
+			statusCode: http.StatusTooManyRequests,
+		},
+		{
+			name: "RequestError",
+			err: &azure.RequestError{
+				DetailedError: autorest.DetailedError{StatusCode: http.StatusNotFound},
+				ServiceError:  &azure.ServiceError{Code: "ResourceGroupNotFound", Message: "not found"},
+				RequestID:     "request-2",
+			},
+			category:   ErrorCategoryNotFound,
+			code:       "ResourceGroupNotFound",
+			statusCode: http.StatusNotFound,
+			requestID:  "request-2",
+		},
+		{
+			name: "DetailedError",
+			err: autorest.DetailedError{
+				StatusCode:   http.StatusServiceUnavailable,
+				ServiceError: []byte(`{"error": {"code": "ServiceUnavailable", "message": "try later"}}`),
+			},
+			category:   ErrorCategoryTransient,
+			code:       "ServiceUnavailable",
+			statusCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:     "EmbeddedJSON",
+			err:      errors.New(`GET https://management.azure.com/...: {"error": {"code": "InvalidAuthenticationToken", "message": "expired"}}`),
+			category: ErrorCategoryAuthFailed,
+			code:     "InvalidAuthenticationToken",
+		},
+		{
+			name:     "DeadlineExceeded",
+			err:      fmt.Errorf("list: %w", context.DeadlineExceeded),
+			category: ErrorCategoryTransient,
+		},
+		{
+			name:     "Unknown",
+			err:      errors.New("something went wrong"),
+			category: ErrorCategoryInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyError(tt.err)
+			if got.Category != tt.category {
+				t.Errorf("category: got %s, want %s", got.Category, tt.category)
+			}
+			if got.Code != tt.code {
+				t.Errorf("code: got %s, want %s", got.Code, tt.code)
+			}
+			if got.StatusCode != tt.statusCode {
+				t.Errorf("status code: got %d, want %d", got.StatusCode, tt.statusCode)
+			}
+			if got.RequestID != tt.requestID {
+				t.Errorf("request id: got %s, want %s", got.RequestID, tt.requestID)
+			}
+		})
+	}
+}
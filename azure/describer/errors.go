@@ -0,0 +1,89 @@
+package describer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// SubscriptionError is the failure of describing a single subscription.
+type SubscriptionError struct {
+	SubscriptionID string
+	Err            error
+}
+
+func (e SubscriptionError) Error() string {
+	return fmt.Sprintf("subscription %s: %v", e.SubscriptionID, e.Err)
+}
+
+func (e SubscriptionError) Unwrap() error {
+	return e.Err
+}
+
+// SubscriptionErrors collects the per-subscription failures of a describe call
+// that went through all its subscriptions.
+type SubscriptionErrors []SubscriptionError
+
+func (e SubscriptionErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d subscription(s) failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+func (e SubscriptionErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+type ScopeType string
+
+const (
+	ScopeTypeSubscription  ScopeType = "subscription"
+	ScopeTypeResourceGroup ScopeType = "resource_group"
+	ScopeTypeResource      ScopeType = "resource"
+)
+
+// FailedScope is a part of a describe job (a subscription, a resource group or a parent resource
+// like a storage account or a key vault) that could not be described.
+type FailedScope struct {
+	Type ScopeType
+	ID   string
+	Err  error
+}
+
+// PartialFailures collects the failed scopes of a describe job which tolerates partial results.
+type PartialFailures struct {
+	mu     sync.Mutex
+	scopes []FailedScope
+}
+
+func (p *PartialFailures) Add(scopeType ScopeType, id string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scopes = append(p.scopes, FailedScope{Type: scopeType, ID: id, Err: err})
+}
+
+func (p *PartialFailures) Scopes() []FailedScope {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]FailedScope(nil), p.scopes...)
+}
+
+// PartialError is returned when some scopes of a describe job failed. The job is PARTIAL if the rest was
+// described and FAILED if no resource was ingested.
+type PartialError struct {
+	FailedScopes []FailedScope
+}
+
+func (e *PartialError) Error() string {
+	msgs := make([]string, 0, len(e.FailedScopes))
+	for _, scope := range e.FailedScopes {
+		msgs = append(msgs, fmt.Sprintf("%s %s: %v", scope.Type, scope.ID, scope.Err))
+	}
+	return fmt.Sprintf("%d scope(s) failed: %s", len(e.FailedScopes), strings.Join(msgs, "; "))
+}
@@ -2,7 +2,7 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/eventgrid/armeventgrid/v2"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
 	"strings"
@@ -10,13 +10,13 @@ import (
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func EventGridDomainTopic(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+func EventGridDomainTopic(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
 	rgs, err := listResourceGroups(ctx, cred, subscription)
 	if err != nil {
 		return nil, err
 	}
 
-	clientFactory, err := armeventgrid.NewClientFactory(subscription, cred, nil)
+	clientFactory, err := armeventgrid.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -62,8 +62,8 @@ func getEventGridDomainTopic(ctx context.Context, v *armeventgrid.DomainTopic) *
 	}
 }
 
-func eventGridDomain(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, resourceGroup string) ([]*armeventgrid.Domain, error) {
-	clientFactory, err := armeventgrid.NewClientFactory(subscription, cred, nil)
+func eventGridDomain(ctx context.Context, cred azcore.TokenCredential, subscription string, resourceGroup string) ([]*armeventgrid.Domain, error) {
+	clientFactory, err := armeventgrid.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -81,14 +81,14 @@ func eventGridDomain(ctx context.Context, cred *azidentity.ClientSecretCredentia
 	return values, nil
 }
 
-func EventGridDomain(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armeventgrid.NewClientFactory(subscription, cred, nil)
+func EventGridDomain(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armeventgrid.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	client := clientFactory.NewDomainsClient()
 
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -147,14 +147,14 @@ func getEventGridDomain(ctx context.Context, domain *armeventgrid.Domain, client
 	return &resource, nil
 }
 
-func EventGridTopic(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armeventgrid.NewClientFactory(subscription, cred, nil)
+func EventGridTopic(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armeventgrid.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	client := clientFactory.NewTopicsClient()
 
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
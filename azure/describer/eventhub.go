@@ -2,7 +2,7 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/eventhub/armeventhub"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
 	"strings"
@@ -10,14 +10,14 @@ import (
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func EventhubNamespace(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+func EventhubNamespace(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	diagnosticClient := monitorClientFactory.NewDiagnosticSettingsClient()
 
-	clientFactory, err := armeventhub.NewClientFactory(subscription, cred, nil)
+	clientFactory, err := armeventhub.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -96,8 +96,8 @@ func getEventHubNamespace(ctx context.Context, diagnosticClient *armmonitor.Diag
 	return &resource, nil
 }
 
-func EventhubNamespaceEventhub(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armeventhub.NewClientFactory(subscription, cred, nil)
+func EventhubNamespaceEventhub(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armeventhub.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -118,6 +118,9 @@ func EventhubNamespaceEventhub(ctx context.Context, cred *azidentity.ClientSecre
 			for pager2.More() {
 				page, err := pager2.NextPage(ctx)
 				if err != nil {
+					if ReportScopeFailure(ctx, ScopeTypeResource, *namespace.ID, err) {
+						break
+					}
 					return nil, err
 				}
 				for _, eh := range page.Value {
@@ -3,10 +3,9 @@ package describer
 import (
 	"context"
 	"fmt"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	abstractions "github.com/microsoft/kiota-abstractions-go"
-	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
 	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
 	"github.com/microsoftgraph/msgraph-sdk-go/applications"
 	"github.com/microsoftgraph/msgraph-sdk-go/auditlogs"
@@ -27,9 +26,8 @@ import (
 	"time"
 )
 
-func AdUsers(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdUsers(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -53,7 +51,7 @@ func AdUsers(ctx context.Context, cred *azidentity.ClientSecretCredential, tenan
 	if err != nil {
 		return nil, err
 	}
-	err = pageIterator.Iterate(context.Background(), func(user models.Userable) bool {
+	err = pageIterator.Iterate(ctx, func(user models.Userable) bool {
 		if user == nil {
 			return true
 		}
@@ -144,9 +142,8 @@ func AdUsers(ctx context.Context, cred *azidentity.ClientSecretCredential, tenan
 	return values, nil
 }
 
-func AdGroup(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdGroup(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -166,7 +163,7 @@ func AdGroup(ctx context.Context, cred *azidentity.ClientSecretCredential, tenan
 	if err != nil {
 		return nil, err
 	}
-	err = pageIterator.Iterate(context.Background(), func(group models.Groupable) bool {
+	err = pageIterator.Iterate(ctx, func(group models.Groupable) bool {
 		if group == nil {
 			return true
 		}
@@ -277,9 +274,8 @@ func AdGroup(ctx context.Context, cred *azidentity.ClientSecretCredential, tenan
 	return values, nil
 }
 
-func AdServicePrinciple(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdServicePrinciple(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -299,7 +295,7 @@ func AdServicePrinciple(ctx context.Context, cred *azidentity.ClientSecretCreden
 	if err != nil {
 		return nil, fmt.Errorf("failed to query apps client: %v", err)
 	}
-	err = appPageIterator.Iterate(context.Background(), func(servicePrincipal *models.ServicePrincipal) bool {
+	err = appPageIterator.Iterate(ctx, func(servicePrincipal *models.ServicePrincipal) bool {
 		if servicePrincipal == nil || servicePrincipal.GetAppId() == nil {
 			return true
 		}
@@ -536,9 +532,8 @@ func AdServicePrinciple(ctx context.Context, cred *azidentity.ClientSecretCreden
 	return values, nil
 }
 
-func AdApplication(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdApplication(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -557,7 +552,7 @@ func AdApplication(ctx context.Context, cred *azidentity.ClientSecretCredential,
 	if err != nil {
 		return nil, err
 	}
-	err = pageIterator.Iterate(context.Background(), func(app models.Applicationable) bool {
+	err = pageIterator.Iterate(ctx, func(app models.Applicationable) bool {
 		if app == nil {
 			return true
 		}
@@ -813,9 +808,8 @@ func AdApplication(ctx context.Context, cred *azidentity.ClientSecretCredential,
 
 //
 
-func AdSignInReport(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdSignInReport(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -836,7 +830,7 @@ func AdSignInReport(ctx context.Context, cred *azidentity.ClientSecretCredential
 	if err != nil {
 		return nil, err
 	}
-	err = pageIterator.Iterate(context.Background(), func(report models.SignInable) bool {
+	err = pageIterator.Iterate(ctx, func(report models.SignInable) bool {
 		if report == nil {
 			return true
 		}
@@ -988,7 +982,7 @@ func AdSignInReport(ctx context.Context, cred *azidentity.ClientSecretCredential
 		return true
 	})
 	if itemErr != nil {
-		return nil, err
+		return nil, itemErr
 	}
 
 	if err != nil {
@@ -998,9 +992,8 @@ func AdSignInReport(ctx context.Context, cred *azidentity.ClientSecretCredential
 	return values, nil
 }
 
-func AdDevice(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdDevice(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -1019,7 +1012,7 @@ func AdDevice(ctx context.Context, cred *azidentity.ClientSecretCredential, tena
 	if err != nil {
 		return nil, err
 	}
-	err = pageIterator.Iterate(context.Background(), func(device models.Deviceable) bool {
+	err = pageIterator.Iterate(ctx, func(device models.Deviceable) bool {
 		if device == nil {
 			return true
 		}
@@ -1058,7 +1051,7 @@ func AdDevice(ctx context.Context, cred *azidentity.ClientSecretCredential, tena
 		return true
 	})
 	if itemErr != nil {
-		return nil, err
+		return nil, itemErr
 	}
 
 	if err != nil {
@@ -1068,9 +1061,8 @@ func AdDevice(ctx context.Context, cred *azidentity.ClientSecretCredential, tena
 	return values, nil
 }
 
-func AdDirectoryRole(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdDirectoryRole(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -1084,7 +1076,7 @@ func AdDirectoryRole(ctx context.Context, cred *azidentity.ClientSecretCredentia
 	if err != nil {
 		return nil, err
 	}
-	err = pageIterator.Iterate(context.Background(), func(role *models.DirectoryRole) bool {
+	err = pageIterator.Iterate(ctx, func(role *models.DirectoryRole) bool {
 		if role == nil {
 			return true
 		}
@@ -1120,7 +1112,7 @@ func AdDirectoryRole(ctx context.Context, cred *azidentity.ClientSecretCredentia
 	})
 
 	if itemErr != nil {
-		return nil, err
+		return nil, itemErr
 	}
 
 	if err != nil {
@@ -1130,9 +1122,8 @@ func AdDirectoryRole(ctx context.Context, cred *azidentity.ClientSecretCredentia
 	return values, nil
 }
 
-func AdDirectorySetting(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdDirectorySetting(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -1151,7 +1142,7 @@ func AdDirectorySetting(ctx context.Context, cred *azidentity.ClientSecretCreden
 	if err != nil {
 		return nil, err
 	}
-	err = pageIterator.Iterate(context.Background(), func(setting models.GroupSettingable) bool {
+	err = pageIterator.Iterate(ctx, func(setting models.GroupSettingable) bool {
 		if setting == nil {
 			return true
 		}
@@ -1185,7 +1176,7 @@ func AdDirectorySetting(ctx context.Context, cred *azidentity.ClientSecretCreden
 	})
 
 	if itemErr != nil {
-		return nil, err
+		return nil, itemErr
 	}
 
 	if err != nil {
@@ -1195,9 +1186,8 @@ func AdDirectorySetting(ctx context.Context, cred *azidentity.ClientSecretCreden
 	return values, nil
 }
 
-func AdDirectoryAuditReport(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdDirectoryAuditReport(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -1216,7 +1206,7 @@ func AdDirectoryAuditReport(ctx context.Context, cred *azidentity.ClientSecretCr
 	if err != nil {
 		return nil, err
 	}
-	err = pageIterator.Iterate(context.Background(), func(audit models.DirectoryAuditable) bool {
+	err = pageIterator.Iterate(ctx, func(audit models.DirectoryAuditable) bool {
 		if audit == nil {
 			return true
 		}
@@ -1364,7 +1354,7 @@ func AdDirectoryAuditReport(ctx context.Context, cred *azidentity.ClientSecretCr
 	})
 
 	if itemErr != nil {
-		return nil, err
+		return nil, itemErr
 	}
 
 	if err != nil {
@@ -1374,9 +1364,8 @@ func AdDirectoryAuditReport(ctx context.Context, cred *azidentity.ClientSecretCr
 	return values, nil
 }
 
-func AdDomain(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdDomain(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -1395,7 +1384,7 @@ func AdDomain(ctx context.Context, cred *azidentity.ClientSecretCredential, tena
 	if err != nil {
 		return nil, err
 	}
-	err = pageIterator.Iterate(context.Background(), func(domain models.Domainable) bool {
+	err = pageIterator.Iterate(ctx, func(domain models.Domainable) bool {
 		if domain == nil {
 			return true
 		}
@@ -1429,7 +1418,7 @@ func AdDomain(ctx context.Context, cred *azidentity.ClientSecretCredential, tena
 	})
 
 	if itemErr != nil {
-		return nil, err
+		return nil, itemErr
 	}
 
 	if err != nil {
@@ -1439,9 +1428,8 @@ func AdDomain(ctx context.Context, cred *azidentity.ClientSecretCredential, tena
 	return values, nil
 }
 
-func AdIdentityProvider(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdIdentityProvider(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -1458,7 +1446,7 @@ func AdIdentityProvider(ctx context.Context, cred *azidentity.ClientSecretCreden
 	if err != nil {
 		return nil, err
 	}
-	err = pageIterator.Iterate(context.Background(), func(ip *models.BuiltInIdentityProvider) bool {
+	err = pageIterator.Iterate(ctx, func(ip *models.BuiltInIdentityProvider) bool {
 		if ip == nil {
 			return true
 		}
@@ -1492,7 +1480,7 @@ func AdIdentityProvider(ctx context.Context, cred *azidentity.ClientSecretCreden
 	})
 
 	if itemErr != nil {
-		return nil, err
+		return nil, itemErr
 	}
 
 	if err != nil {
@@ -1502,9 +1490,8 @@ func AdIdentityProvider(ctx context.Context, cred *azidentity.ClientSecretCreden
 	return values, nil
 }
 
-func AdSecurityDefaultsPolicy(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdSecurityDefaultsPolicy(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -1544,9 +1531,8 @@ func AdSecurityDefaultsPolicy(ctx context.Context, cred *azidentity.ClientSecret
 	return values, nil
 }
 
-func AdAuthorizationPolicy(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdAuthorizationPolicy(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -1610,9 +1596,8 @@ func AdAuthorizationPolicy(ctx context.Context, cred *azidentity.ClientSecretCre
 	return values, nil
 }
 
-func AdConditionalAccessPolicy(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdConditionalAccessPolicy(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -1631,7 +1616,7 @@ func AdConditionalAccessPolicy(ctx context.Context, cred *azidentity.ClientSecre
 	if err != nil {
 		return nil, err
 	}
-	err = pageIterator.Iterate(context.Background(), func(p models.ConditionalAccessPolicyable) bool {
+	err = pageIterator.Iterate(ctx, func(p models.ConditionalAccessPolicyable) bool {
 		if p == nil {
 			return true
 		}
@@ -1868,7 +1853,7 @@ func AdConditionalAccessPolicy(ctx context.Context, cred *azidentity.ClientSecre
 	})
 
 	if itemErr != nil {
-		return nil, err
+		return nil, itemErr
 	}
 
 	if err != nil {
@@ -1878,9 +1863,8 @@ func AdConditionalAccessPolicy(ctx context.Context, cred *azidentity.ClientSecre
 	return values, nil
 }
 
-func AdAdminConsentRequestPolicy(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdAdminConsentRequestPolicy(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -1942,9 +1926,8 @@ func AdAdminConsentRequestPolicy(ctx context.Context, cred *azidentity.ClientSec
 	return values, nil
 }
 
-func AdUserRegistrationDetails(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdUserRegistrationDetails(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -1960,7 +1943,7 @@ func AdUserRegistrationDetails(ctx context.Context, cred *azidentity.ClientSecre
 	if err != nil {
 		return nil, err
 	}
-	err = pageIterator.Iterate(context.Background(), func(user *models.UserRegistrationDetails) bool {
+	err = pageIterator.Iterate(ctx, func(user *models.UserRegistrationDetails) bool {
 		if user == nil {
 			return true
 		}
@@ -2009,9 +1992,8 @@ func AdUserRegistrationDetails(ctx context.Context, cred *azidentity.ClientSecre
 	return values, nil
 }
 
-func AdGroupMembership(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdGroupMembership(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -2031,7 +2013,7 @@ func AdGroupMembership(ctx context.Context, cred *azidentity.ClientSecretCredent
 	if err != nil {
 		return nil, err
 	}
-	err = pageIterator.Iterate(context.Background(), func(group models.Groupable) bool {
+	err = pageIterator.Iterate(ctx, func(group models.Groupable) bool {
 		if group == nil {
 			return true
 		}
@@ -2092,9 +2074,8 @@ func AdGroupMembership(ctx context.Context, cred *azidentity.ClientSecretCredent
 	return values, nil
 }
 
-func AdAppRegistration(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdAppRegistration(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -2113,7 +2094,7 @@ func AdAppRegistration(ctx context.Context, cred *azidentity.ClientSecretCredent
 	if err != nil {
 		return nil, err
 	}
-	err = pageIterator.Iterate(context.Background(), func(app models.Applicationable) bool {
+	err = pageIterator.Iterate(ctx, func(app models.Applicationable) bool {
 		if app == nil {
 			return true
 		}
@@ -2367,9 +2348,8 @@ func AdAppRegistration(ctx context.Context, cred *azidentity.ClientSecretCredent
 	return values, nil
 }
 
-func AdEnterpriseApplication(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdEnterpriseApplication(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -2390,7 +2370,7 @@ func AdEnterpriseApplication(ctx context.Context, cred *azidentity.ClientSecretC
 	if err != nil {
 		return nil, fmt.Errorf("failed to query apps client: %v", err)
 	}
-	err = appPageIterator.Iterate(context.Background(), func(servicePrincipal *models.ServicePrincipal) bool {
+	err = appPageIterator.Iterate(ctx, func(servicePrincipal *models.ServicePrincipal) bool {
 		if servicePrincipal == nil || servicePrincipal.GetAppId() == nil {
 			return true
 		}
@@ -2622,9 +2602,8 @@ func AdEnterpriseApplication(ctx context.Context, cred *azidentity.ClientSecretC
 	return values, nil
 }
 
-func AdManagedIdentity(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdManagedIdentity(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -2645,7 +2624,7 @@ func AdManagedIdentity(ctx context.Context, cred *azidentity.ClientSecretCredent
 	if err != nil {
 		return nil, fmt.Errorf("failed to query apps client: %v", err)
 	}
-	err = appPageIterator.Iterate(context.Background(), func(servicePrincipal *models.ServicePrincipal) bool {
+	err = appPageIterator.Iterate(ctx, func(servicePrincipal *models.ServicePrincipal) bool {
 		if servicePrincipal == nil || servicePrincipal.GetAppId() == nil {
 			return true
 		}
@@ -2886,9 +2865,8 @@ func AdManagedIdentity(ctx context.Context, cred *azidentity.ClientSecretCredent
 	return values, nil
 }
 
-func AdMicrosoftApplication(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdMicrosoftApplication(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -2912,7 +2890,7 @@ func AdMicrosoftApplication(ctx context.Context, cred *azidentity.ClientSecretCr
 	if err != nil {
 		return nil, fmt.Errorf("failed to query apps client: %v", err)
 	}
-	err = appPageIterator.Iterate(context.Background(), func(servicePrincipal *models.ServicePrincipal) bool {
+	err = appPageIterator.Iterate(ctx, func(servicePrincipal *models.ServicePrincipal) bool {
 		if servicePrincipal == nil || servicePrincipal.GetAppId() == nil {
 			return true
 		}
@@ -3144,9 +3122,8 @@ func AdMicrosoftApplication(ctx context.Context, cred *azidentity.ClientSecretCr
 	return values, nil
 }
 
-func AdTenant(ctx context.Context, cred *azidentity.ClientSecretCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
-	scopes := []string{"https://graph.microsoft.com/.default"}
-	client, err := msgraphsdk.NewGraphServiceClientWithCredentials(cred, scopes)
+func AdTenant(ctx context.Context, cred azcore.TokenCredential, tenantId string, stream *StreamSender) ([]Resource, error) {
+	client, err := NewGraphServiceClient(ctx, cred)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
@@ -3165,7 +3142,7 @@ func AdTenant(ctx context.Context, cred *azidentity.ClientSecretCredential, tena
 	if err != nil {
 		return nil, err
 	}
-	err = pageIterator.Iterate(context.Background(), func(org *models.Organization) bool {
+	err = pageIterator.Iterate(ctx, func(org *models.Organization) bool {
 		if org == nil {
 			return true
 		}
@@ -3219,7 +3196,7 @@ func AdTenant(ctx context.Context, cred *azidentity.ClientSecretCredential, tena
 	})
 
 	if itemErr != nil {
-		return nil, err
+		return nil, itemErr
 	}
 
 	if err != nil {
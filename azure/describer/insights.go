@@ -2,15 +2,15 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
 	"github.com/opengovern/og-azure-describer/azure/model"
 	"strings"
 	"time"
 )
 
-func DiagnosticSetting(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+func DiagnosticSetting(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -61,8 +61,8 @@ func getDiagnosticSetting(ctx context.Context, diagnosticSetting *armmonitor.Dia
 	return &resource
 }
 
-func LogAlert(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+func LogAlert(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -108,8 +108,8 @@ func getLogAlert(ctx context.Context, logAlert *armmonitor.ActivityLogAlertResou
 	return &resource
 }
 
-func LogProfile(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+func LogProfile(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -157,6 +157,24 @@ func getLogProfile(ctx context.Context, logProfile *armmonitor.LogProfileResourc
 	return &resource
 }
 
+// listDiagnosticSettings lists the diagnostic settings of a resource, none if the context excludes the child resources.
+func listDiagnosticSettings(ctx context.Context, client *armmonitor.DiagnosticSettingsClient, resourceID string) ([]*armmonitor.DiagnosticSettingsResource, error) {
+	if !IncludeChildResources(ctx) {
+		return nil, nil
+	}
+
+	var values []*armmonitor.DiagnosticSettingsResource
+	pager := client.NewListPager(resourceID, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, page.Value...)
+	}
+	return values, nil
+}
+
 func getMonitoringIntervalForGranularity(granularity string) string {
 	switch strings.ToUpper(granularity) {
 	case "DAILY":
@@ -183,16 +201,20 @@ func getMonitoringStartDateForGranularity(granularity string) string {
 	return time.Now().UTC().AddDate(0, 0, -5).Format(time.RFC3339)
 }
 
-func listAzureMonitorMetricStatistics(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, granularity string, metricNameSpace string, metricNames string, dimensionValue string) ([]model.MonitoringMetric, error) {
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+func listAzureMonitorMetricStatistics(ctx context.Context, cred azcore.TokenCredential, subscription string, granularity string, metricNameSpace string, metricNames string, dimensionValue string) ([]model.MonitoringMetric, error) {
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	metricsClient := monitorClientFactory.NewMetricsClient()
 
+	granularity = string(GetMetricGranularityFromContext(ctx, MetricGranularity(granularity)))
 	interval := getMonitoringIntervalForGranularity(granularity)
 	aggregation := "average,count,maximum,minimum,total"
 	timeSpan := getMonitoringStartDateForGranularity(granularity) + "/" + time.Now().UTC().AddDate(0, 0, 1).Format(time.RFC3339) // Retrieve data within a year
+	if window := GetTimeWindowFromContext(ctx); window != nil {
+		timeSpan = window.From.UTC().Format(time.RFC3339) + "/" + window.To.UTC().Format(time.RFC3339)
+	}
 	orderBy := "timestamp"
 	top := int32(1000) // Maximum number of record fetch with given interval
 	filter := ""
@@ -235,8 +257,8 @@ func listAzureMonitorMetricStatistics(ctx context.Context, cred *azidentity.Clie
 	return values, nil
 }
 
-func AutoscaleSetting(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+func AutoscaleSetting(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -2,7 +2,8 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azcertificates"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
@@ -13,8 +14,8 @@ import (
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func KeyVaultKey(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armkeyvault.NewClientFactory(subscription, cred, nil)
+func KeyVaultKey(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armkeyvault.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -45,6 +46,9 @@ func KeyVaultKey(ctx context.Context, cred *azidentity.ClientSecretCredential, s
 				for pager2.More() {
 					page2, err := pager2.NextPage(ctx)
 					if err != nil {
+						if ReportScopeFailure(ctx, ScopeTypeResource, *vault.ID, err) {
+							return nil, nil
+						}
 						return nil, err
 					}
 					result = append(result, page2.Value...)
@@ -85,7 +89,7 @@ func KeyVaultKey(ctx context.Context, cred *azidentity.ClientSecretCredential, s
 				var vvv []Resource
 				for _, r := range results {
 					if r.Error != nil {
-						return nil, err
+						return nil, r.Error
 					}
 					if r.Value == nil {
 						continue
@@ -100,7 +104,7 @@ func KeyVaultKey(ctx context.Context, cred *azidentity.ClientSecretCredential, s
 	results := wpe.Run()
 	for _, result := range results {
 		if result.Error != nil {
-			return nil, err
+			return nil, result.Error
 		}
 		if result.Value == nil {
 			continue
@@ -145,14 +149,14 @@ func getKeyVaultKey(ctx context.Context, keysClient *armkeyvault.KeysClient, vCo
 	}, nil
 }
 
-func KeyVault(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armkeyvault.NewClientFactory(subscription, cred, nil)
+func KeyVault(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armkeyvault.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	vaultsClient := clientFactory.NewVaultsClient()
 
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -163,29 +167,94 @@ func KeyVault(ctx context.Context, cred *azidentity.ClientSecretCredential, subs
 		Top: &maxResults,
 	}
 	var values []Resource
-	pager := vaultsClient.NewListPager(options)
-	for pager.More() {
-		page, err := pager.NextPage(ctx)
-		if err != nil {
-			return nil, err
-		}
-		for _, vault := range page.Value {
+	describe := func(vaults []*armkeyvault.Resource) error {
+		for _, vault := range vaults {
+			if !InScope(ctx, *vault.ID, vault.Tags) {
+				continue
+			}
 			resource, err := getKeyVault(ctx, vault, vaultsClient, diagnosticClient)
 			if err != nil {
-				return nil, err
+				return err
 			}
 			if stream != nil {
 				if err := (*stream)(*resource); err != nil {
-					return nil, err
+					return err
 				}
 			} else {
 				values = append(values, *resource)
 			}
 		}
+		return nil
+	}
+
+	if resourceGroups := GetResourceGroupsFromContext(ctx); len(resourceGroups) > 0 {
+		for _, resourceGroup := range resourceGroups {
+			pager := vaultsClient.NewListByResourceGroupPager(resourceGroup, nil)
+			for pager.More() {
+				page, err := pager.NextPage(ctx)
+				if err != nil {
+					if isResourceGroupNotFound(err) || ReportScopeFailure(ctx, ScopeTypeResourceGroup, resourceGroupID(subscription, resourceGroup), err) {
+						break
+					}
+					return nil, err
+				}
+				// the resource group listing returns the vaults themselves, getKeyVault only needs their resource fields
+				var vaults []*armkeyvault.Resource
+				for _, vault := range page.Value {
+					vaults = append(vaults, &armkeyvault.Resource{
+						ID:       vault.ID,
+						Location: vault.Location,
+						Name:     vault.Name,
+						Tags:     vault.Tags,
+						Type:     vault.Type,
+					})
+				}
+				if err := describe(vaults); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return values, nil
+	}
+
+	pager := vaultsClient.NewListPager(options)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := describe(page.Value); err != nil {
+			return nil, err
+		}
 	}
 	return values, nil
 }
 
+func KeyVaultByID(ctx context.Context, cred azcore.TokenCredential, id *arm.ResourceID) (*Resource, error) {
+	clientFactory, err := armkeyvault.NewClientFactory(id.SubscriptionID, cred, ClientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+	vaultsClient := clientFactory.NewVaultsClient()
+
+	monitorClientFactory, err := armmonitor.NewClientFactory(id.SubscriptionID, cred, ClientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	vault, err := vaultsClient.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return getKeyVault(ctx, &armkeyvault.Resource{
+		ID:       vault.ID,
+		Location: vault.Location,
+		Name:     vault.Name,
+		Tags:     vault.Tags,
+		Type:     vault.Type,
+	}, vaultsClient, monitorClientFactory.NewDiagnosticSettingsClient())
+}
+
 func getKeyVault(ctx context.Context, vault *armkeyvault.Resource, vaultsClient *armkeyvault.VaultsClient, diagnosticClient *armmonitor.DiagnosticSettingsClient) (*Resource, error) {
 	name := *vault.Name
 	resourceGroup := strings.Split(*vault.ID, "/")[4]
@@ -195,18 +264,10 @@ func getKeyVault(ctx context.Context, vault *armkeyvault.Resource, vaultsClient
 		return nil, err
 	}
 
-	var insightsListOp []*armmonitor.DiagnosticSettingsResource
-	pager := diagnosticClient.NewListPager(*vault.ID, nil)
+	insightsListOp, err := listDiagnosticSettings(ctx, diagnosticClient, *vault.ID)
 	if err != nil {
 		return nil, err
 	}
-	for pager.More() {
-		page, err := pager.NextPage(ctx)
-		if err != nil {
-			return nil, err
-		}
-		insightsListOp = append(insightsListOp, page.Value...)
-	}
 
 	resource := Resource{
 		ID:       *vault.ID,
@@ -224,8 +285,8 @@ func getKeyVault(ctx context.Context, vault *armkeyvault.Resource, vaultsClient
 	return &resource, nil
 }
 
-func DeletedVault(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armkeyvault.NewClientFactory(subscription, cred, nil)
+func DeletedVault(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armkeyvault.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -269,8 +330,8 @@ func getDeletedVault(ctx context.Context, vault *armkeyvault.DeletedVault) *Reso
 	return &resource
 }
 
-func KeyVaultManagedHardwareSecurityModule(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+func KeyVaultManagedHardwareSecurityModule(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -278,7 +339,7 @@ func KeyVaultManagedHardwareSecurityModule(ctx context.Context, cred *azidentity
 
 	maxResults := int32(100)
 
-	clientFactory, err := armkeyvault.NewClientFactory(subscription, cred, nil)
+	clientFactory, err := armkeyvault.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -315,14 +376,9 @@ func KeyVaultManagedHardwareSecurityModule(ctx context.Context, cred *azidentity
 func getKeyVaultManagedHardwareSecurityModule(ctx context.Context, client *armmonitor.DiagnosticSettingsClient, vault *armkeyvault.ManagedHsm) (*Resource, error) {
 	resourceGroup := strings.Split(*vault.ID, "/")[4]
 
-	var keyvaultListOp []*armmonitor.DiagnosticSettingsResource
-	pager := client.NewListPager(*vault.ID, nil)
-	for pager.More() {
-		page, err := pager.NextPage(ctx)
-		if err != nil {
-			return nil, err
-		}
-		keyvaultListOp = append(keyvaultListOp, page.Value...)
+	keyvaultListOp, err := listDiagnosticSettings(ctx, client, *vault.ID)
+	if err != nil {
+		return nil, err
 	}
 
 	resource := Resource{
@@ -340,8 +396,8 @@ func getKeyVaultManagedHardwareSecurityModule(ctx context.Context, client *armmo
 	return &resource, nil
 }
 
-func KeyVaultKeyVersion(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armkeyvault.NewClientFactory(subscription, cred, nil)
+func KeyVaultKeyVersion(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armkeyvault.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -372,6 +428,9 @@ func KeyVaultKeyVersion(ctx context.Context, cred *azidentity.ClientSecretCreden
 				for pager2.More() {
 					page2, err := pager2.NextPage(ctx)
 					if err != nil {
+						if ReportScopeFailure(ctx, ScopeTypeResource, *vault.ID, err) {
+							return nil, nil
+						}
 						return nil, err
 					}
 					result = append(result, page2.Value...)
@@ -394,12 +453,12 @@ func KeyVaultKeyVersion(ctx context.Context, cred *azidentity.ClientSecretCreden
 				var vvv []Resource
 				for _, r := range results {
 					if r.Error != nil {
-						return nil, err
+						return nil, r.Error
 					}
 					if r.Value == nil {
 						continue
 					}
-					vvv = append(vvv, r.Value.(Resource))
+					vvv = append(vvv, r.Value.([]Resource)...)
 				}
 				return vvv, nil
 			})
@@ -409,7 +468,7 @@ func KeyVaultKeyVersion(ctx context.Context, cred *azidentity.ClientSecretCreden
 	results := wpe.Run()
 	for _, result := range results {
 		if result.Error != nil {
-			return nil, err
+			return nil, result.Error
 		}
 		if result.Value == nil {
 			continue
@@ -472,8 +531,8 @@ func GetKeyVaultKeyVersion(ctx context.Context, resourceGroup string, vault *arm
 	return &resource
 }
 
-func KeyVaultCertificate(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armkeyvault.NewClientFactory(subscription, cred, nil)
+func KeyVaultCertificate(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armkeyvault.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -493,6 +552,9 @@ func KeyVaultCertificate(ctx context.Context, cred *azidentity.ClientSecretCrede
 		for _, vault := range page.Value {
 			resource, err := getKeyVaultCertificates(ctx, cred, vault, vaultsClient)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *vault.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, res := range resource {
@@ -509,7 +571,7 @@ func KeyVaultCertificate(ctx context.Context, cred *azidentity.ClientSecretCrede
 	return values, nil
 }
 
-func getKeyVaultCertificates(ctx context.Context, cred *azidentity.ClientSecretCredential, vault *armkeyvault.Resource, vaultsClient *armkeyvault.VaultsClient) ([]Resource, error) {
+func getKeyVaultCertificates(ctx context.Context, cred azcore.TokenCredential, vault *armkeyvault.Resource, vaultsClient *armkeyvault.VaultsClient) ([]Resource, error) {
 	name := *vault.Name
 	resourceGroup := strings.Split(*vault.ID, "/")[4]
 
@@ -2,7 +2,7 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
 	"strings"
@@ -10,13 +10,13 @@ import (
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func LoadBalancer(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewLoadBalancersClient(subscription, cred, nil)
+func LoadBalancer(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewLoadBalancersClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -75,13 +75,13 @@ func getLoadBalancer(ctx context.Context, diagnosticClient *armmonitor.Diagnosti
 	return &resource, nil
 }
 
-func LoadBalancerBackendAddressPool(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewLoadBalancersClient(subscription, cred, nil)
+func LoadBalancerBackendAddressPool(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewLoadBalancersClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 
-	addressClient, err := armnetwork.NewLoadBalancerBackendAddressPoolsClient(subscription, cred, nil)
+	addressClient, err := armnetwork.NewLoadBalancerBackendAddressPoolsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -96,6 +96,9 @@ func LoadBalancerBackendAddressPool(ctx context.Context, cred *azidentity.Client
 		for _, loadBalancer := range page.Value {
 			resources, err := listLoadBalancerBackendAddressPools(ctx, addressClient, loadBalancer)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *loadBalancer.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, resource := range resources {
@@ -151,12 +154,12 @@ func getLoadBalancerBackendAddressPools(ctx context.Context, loadBalancer *armne
 	return &resource
 }
 
-func LoadBalancerNatRule(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewLoadBalancersClient(subscription, cred, nil)
+func LoadBalancerNatRule(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewLoadBalancersClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
-	natRulesClient, err := armnetwork.NewInboundNatRulesClient(subscription, cred, nil)
+	natRulesClient, err := armnetwork.NewInboundNatRulesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -171,6 +174,9 @@ func LoadBalancerNatRule(ctx context.Context, cred *azidentity.ClientSecretCrede
 		for _, loadBalancer := range page.Value {
 			resources, err := listLoadBalancerNatRules(ctx, natRulesClient, loadBalancer)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *loadBalancer.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, resource := range resources {
@@ -223,12 +229,12 @@ func getLoadBalancerNatRule(ctx context.Context, loadBalancer *armnetwork.LoadBa
 	return &resource
 }
 
-func LoadBalancerOutboundRule(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewLoadBalancersClient(subscription, cred, nil)
+func LoadBalancerOutboundRule(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewLoadBalancersClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
-	outboundRulesClient, err := armnetwork.NewLoadBalancerOutboundRulesClient(subscription, cred, nil)
+	outboundRulesClient, err := armnetwork.NewLoadBalancerOutboundRulesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -243,6 +249,9 @@ func LoadBalancerOutboundRule(ctx context.Context, cred *azidentity.ClientSecret
 		for _, loadBalancer := range page.Value {
 			resources, err := listLoadBalancerOutboundRules(ctx, outboundRulesClient, loadBalancer)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *loadBalancer.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, resource := range resources {
@@ -295,12 +304,12 @@ func getLoadBalancerOutboundRule(ctx context.Context, loadBalancer *armnetwork.L
 	return &resource
 }
 
-func LoadBalancerProbe(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewLoadBalancersClient(subscription, cred, nil)
+func LoadBalancerProbe(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewLoadBalancersClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
-	probesClient, err := armnetwork.NewLoadBalancerProbesClient(subscription, cred, nil)
+	probesClient, err := armnetwork.NewLoadBalancerProbesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -315,6 +324,9 @@ func LoadBalancerProbe(ctx context.Context, cred *azidentity.ClientSecretCredent
 		for _, loadBalancer := range page.Value {
 			resources, err := listLoadBalancerProbes(ctx, probesClient, loadBalancer)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *loadBalancer.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, resource := range resources {
@@ -367,12 +379,12 @@ func getLoadBalancerProbe(ctx context.Context, loadBalancer *armnetwork.LoadBala
 	return &resource
 }
 
-func LoadBalancerRule(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewLoadBalancersClient(subscription, cred, nil)
+func LoadBalancerRule(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewLoadBalancersClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
-	rulesClient, err := armnetwork.NewLoadBalancerLoadBalancingRulesClient(subscription, cred, nil)
+	rulesClient, err := armnetwork.NewLoadBalancerLoadBalancingRulesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -387,6 +399,9 @@ func LoadBalancerRule(ctx context.Context, cred *azidentity.ClientSecretCredenti
 		for _, loadBalancer := range page.Value {
 			resources, err := listLoadBalancerRules(ctx, rulesClient, loadBalancer)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *loadBalancer.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, resource := range resources {
@@ -5,13 +5,13 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/managedservices/armmanagedservices"
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func LighthouseDefinition(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armmanagedservices.NewClientFactory(cred, nil)
+func LighthouseDefinition(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armmanagedservices.NewClientFactory(cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -59,8 +59,8 @@ func getLighthouseDefinition(_ context.Context, lighthouseDefinition *armmanaged
 	return &resource
 }
 
-func LighthouseAssignments(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armmanagedservices.NewClientFactory(cred, nil)
+func LighthouseAssignments(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armmanagedservices.NewClientFactory(cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -2,7 +2,7 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/managementgroups/armmanagementgroups"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armlocks"
 	"strings"
@@ -10,8 +10,8 @@ import (
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func ManagementGroup(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armmanagementgroups.NewClientFactory(cred, nil)
+func ManagementGroup(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armmanagementgroups.NewClientFactory(cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -59,8 +59,8 @@ func getManagementGroup(ctx context.Context, client *armmanagementgroups.Client,
 	return resource, nil
 }
 
-func ManagementLock(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armlocks.NewClientFactory(subscription, cred, nil)
+func ManagementLock(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armlocks.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
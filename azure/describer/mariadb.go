@@ -2,15 +2,15 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/mariadb/armmariadb"
 	"strings"
 
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func MariadbServer(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armmariadb.NewClientFactory(subscription, cred, nil)
+func MariadbServer(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armmariadb.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -55,8 +55,8 @@ func getMariadbServer(ctx context.Context, server *armmariadb.Server) *Resource
 	return &resource
 }
 
-func MariadbDatabases(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armmariadb.NewClientFactory(subscription, cred, nil)
+func MariadbDatabases(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armmariadb.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +73,9 @@ func MariadbDatabases(ctx context.Context, cred *azidentity.ClientSecretCredenti
 		for _, server := range page.Value {
 			resource, err := listMariadbServerDatabases(ctx, databaseClient, server)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *server.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			if stream != nil {
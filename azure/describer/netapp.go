@@ -2,15 +2,15 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/netapp/armnetapp/v2"
 	"strings"
 
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func NetAppAccount(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetapp.NewAccountsClient(subscription, cred, nil)
+func NetAppAccount(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetapp.NewAccountsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -53,13 +53,13 @@ func getNetAppAccount(ctx context.Context, v *armnetapp.Account) *Resource {
 	return &resource
 }
 
-func NetAppCapacityPool(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetapp.NewAccountsClient(subscription, cred, nil)
+func NetAppCapacityPool(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetapp.NewAccountsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 
-	poolsClient, err := armnetapp.NewPoolsClient(subscription, cred, nil)
+	poolsClient, err := armnetapp.NewPoolsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -74,6 +74,9 @@ func NetAppCapacityPool(ctx context.Context, cred *azidentity.ClientSecretCreden
 		for _, v := range page.Value {
 			resources, err := listNetAppAccountPools(ctx, poolsClient, v)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *v.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			if stream != nil {
@@ -2,7 +2,8 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/dns/armdns"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/dnsresolver/armdnsresolver"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
@@ -15,8 +16,8 @@ import (
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func NetworkInterface(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewInterfacesClient(subscription, cred, nil)
+func NetworkInterface(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewInterfacesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -59,12 +60,12 @@ func getNetworkInterface(ctx context.Context, v *armnetwork.Interface) *Resource
 	return &resource
 }
 
-func NetworkWatcherFlowLog(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	logsClient, err := armnetwork.NewFlowLogsClient(subscription, cred, nil)
+func NetworkWatcherFlowLog(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	logsClient, err := armnetwork.NewFlowLogsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
-	watcherClient, err := armnetwork.NewWatchersClient(subscription, cred, nil)
+	watcherClient, err := armnetwork.NewWatchersClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -79,6 +80,9 @@ func NetworkWatcherFlowLog(ctx context.Context, cred *azidentity.ClientSecretCre
 		for _, watcher := range page.Value {
 			resources, err := listWatcherFlowLogs(ctx, logsClient, watcher)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *watcher.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, resource := range resources {
@@ -131,12 +135,12 @@ func getWatcherFlowLog(ctx context.Context, watcher *armnetwork.Watcher, v *armn
 	return &resource
 }
 
-func Subnet(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	subnetsClient, err := armnetwork.NewSubnetsClient(subscription, cred, nil)
+func Subnet(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	subnetsClient, err := armnetwork.NewSubnetsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
-	virtualnetworkClient, err := armnetwork.NewVirtualNetworksClient(subscription, cred, nil)
+	virtualnetworkClient, err := armnetwork.NewVirtualNetworksClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -151,6 +155,9 @@ func Subnet(ctx context.Context, cred *azidentity.ClientSecretCredential, subscr
 		for _, virtualnetwork := range page.Value {
 			resources, err := listVirtualNetworkSubnets(ctx, subnetsClient, virtualnetwork)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *virtualnetwork.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, resource := range resources {
@@ -203,8 +210,8 @@ func getVirtualNetworkSubnet(ctx context.Context, virtualnetwork *armnetwork.Vir
 	return &resource
 }
 
-func VirtualNetwork(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewVirtualNetworksClient(subscription, cred, nil)
+func VirtualNetwork(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewVirtualNetworksClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -248,13 +255,13 @@ func getVirtualNetwork(ctx context.Context, v *armnetwork.VirtualNetwork) *Resou
 	return &resource
 }
 
-func ApplicationGateway(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewApplicationGatewaysClient(subscription, cred, nil)
+func ApplicationGateway(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewApplicationGatewaysClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -312,42 +319,89 @@ func getApplicationGateway(ctx context.Context, diagnosticClient *armmonitor.Dia
 	return &resource, nil
 }
 
-func NetworkSecurityGroup(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewSecurityGroupsClient(subscription, cred, nil)
+func NetworkSecurityGroup(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewSecurityGroupsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	diagnosticClient := monitorClientFactory.NewDiagnosticSettingsClient()
 
-	pager := client.NewListAllPager(nil)
 	var values []Resource
-	for pager.More() {
-		page, err := pager.NextPage(ctx)
-		if err != nil {
-			return nil, err
-		}
-		for _, networkSecurityGroup := range page.Value {
+	describe := func(networkSecurityGroups []*armnetwork.SecurityGroup) error {
+		for _, networkSecurityGroup := range networkSecurityGroups {
+			if !InScope(ctx, *networkSecurityGroup.ID, networkSecurityGroup.Tags) {
+				continue
+			}
 			resource, err := getNetworkSecurityGroup(ctx, diagnosticClient, networkSecurityGroup)
 			if err != nil {
-				return nil, err
+				return err
 			}
 			if stream != nil {
 				if err := (*stream)(*resource); err != nil {
-					return nil, err
+					return err
 				}
 			} else {
 				values = append(values, *resource)
 			}
 		}
+		return nil
+	}
+
+	if resourceGroups := GetResourceGroupsFromContext(ctx); len(resourceGroups) > 0 {
+		for _, resourceGroup := range resourceGroups {
+			pager := client.NewListPager(resourceGroup, nil)
+			for pager.More() {
+				page, err := pager.NextPage(ctx)
+				if err != nil {
+					if isResourceGroupNotFound(err) || ReportScopeFailure(ctx, ScopeTypeResourceGroup, resourceGroupID(subscription, resourceGroup), err) {
+						break
+					}
+					return nil, err
+				}
+				if err := describe(page.Value); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return values, nil
+	}
+
+	pager := client.NewListAllPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := describe(page.Value); err != nil {
+			return nil, err
+		}
 	}
 	return values, nil
 }
 
+func NetworkSecurityGroupByID(ctx context.Context, cred azcore.TokenCredential, id *arm.ResourceID) (*Resource, error) {
+	client, err := armnetwork.NewSecurityGroupsClient(id.SubscriptionID, cred, ClientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	monitorClientFactory, err := armmonitor.NewClientFactory(id.SubscriptionID, cred, ClientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	networkSecurityGroup, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return getNetworkSecurityGroup(ctx, monitorClientFactory.NewDiagnosticSettingsClient(), &networkSecurityGroup.SecurityGroup)
+}
+
 func getNetworkSecurityGroup(ctx context.Context, diagnosticClient *armmonitor.DiagnosticSettingsClient, networkSecurityGroup *armnetwork.SecurityGroup) (*Resource, error) {
 	resourceGroup := strings.Split(*networkSecurityGroup.ID, "/")[4]
 
@@ -382,8 +436,8 @@ func getNetworkSecurityGroup(ctx context.Context, diagnosticClient *armmonitor.D
 	return &resource, nil
 }
 
-func NetworkWatcher(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewWatchersClient(subscription, cred, nil)
+func NetworkWatcher(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewWatchersClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -427,8 +481,8 @@ func getNetworkWatcher(ctx context.Context, networkWatcher *armnetwork.Watcher)
 	return &resource
 }
 
-func RouteTables(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewRouteTablesClient(subscription, cred, nil)
+func RouteTables(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewRouteTablesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -471,8 +525,8 @@ func getRouteTable(ctx context.Context, routeTable *armnetwork.RouteTable) *Reso
 	return &resource
 }
 
-func NetworkApplicationSecurityGroups(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewApplicationSecurityGroupsClient(subscription, cred, nil)
+func NetworkApplicationSecurityGroups(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewApplicationSecurityGroupsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -516,8 +570,8 @@ func getApplicationSecurityGroup(ctx context.Context, applicationSecurityGroup *
 	return &resource
 }
 
-func NetworkAzureFirewall(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewAzureFirewallsClient(subscription, cred, nil)
+func NetworkAzureFirewall(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewAzureFirewallsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -561,8 +615,8 @@ func getAzureFirewall(ctx context.Context, azureFirewall *armnetwork.AzureFirewa
 	return &resource
 }
 
-func ExpressRouteCircuit(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewExpressRouteCircuitsClient(subscription, cred, nil)
+func ExpressRouteCircuit(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewExpressRouteCircuitsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -605,8 +659,8 @@ func getExpressRouteCircuit(ctx context.Context, expressRouteCircuit *armnetwork
 	return &resource
 }
 
-func VirtualNetworkGateway(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewVirtualNetworkGatewaysClient(subscription, cred, nil)
+func VirtualNetworkGateway(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewVirtualNetworkGatewaysClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -697,8 +751,8 @@ func getVirtualNetworkGateway(ctx context.Context, client *armnetwork.VirtualNet
 	return &resource, nil
 }
 
-func FirewallPolicy(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewFirewallPoliciesClient(subscription, cred, nil)
+func FirewallPolicy(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewFirewallPoliciesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -742,8 +796,8 @@ func getFirewallPolicy(ctx context.Context, firewallPolicy *armnetwork.FirewallP
 	return &resource
 }
 
-func LocalNetworkGateway(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewLocalNetworkGatewaysClient(subscription, cred, nil)
+func LocalNetworkGateway(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewLocalNetworkGatewaysClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -806,8 +860,8 @@ func getLocalNetworkGateway(ctx context.Context, localNetworkGateway *armnetwork
 	return &resource
 }
 
-func NatGateway(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewNatGatewaysClient(subscription, cred, nil)
+func NatGateway(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewNatGatewaysClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -870,8 +924,8 @@ func getNatGateway(ctx context.Context, natGateway *armnetwork.NatGateway) *Reso
 	return &resource
 }
 
-func PrivateLinkService(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewPrivateLinkServicesClient(subscription, cred, nil)
+func PrivateLinkService(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewPrivateLinkServicesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -934,8 +988,8 @@ func getPrivateLinkService(ctx context.Context, privateLinkService *armnetwork.P
 	return &resource
 }
 
-func RouteFilter(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewRouteFiltersClient(subscription, cred, nil)
+func RouteFilter(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewRouteFiltersClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -979,8 +1033,8 @@ func getRouteFilter(ctx context.Context, routeFilter *armnetwork.RouteFilter) *R
 	return &resource
 }
 
-func VpnGateway(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewVPNGatewaysClient(subscription, cred, nil)
+func VpnGateway(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewVPNGatewaysClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1024,12 +1078,12 @@ func getVpnGateway(ctx context.Context, vpnGateway *armnetwork.VPNGateway) *Reso
 	return &resource
 }
 
-func NetworkVpnGatewaysVpnConnections(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewVPNGatewaysClient(subscription, cred, nil)
+func NetworkVpnGatewaysVpnConnections(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewVPNGatewaysClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
-	connClient, err := armnetwork.NewVPNConnectionsClient(subscription, cred, nil)
+	connClient, err := armnetwork.NewVPNConnectionsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1044,6 +1098,9 @@ func NetworkVpnGatewaysVpnConnections(ctx context.Context, cred *azidentity.Clie
 		for _, vpnGateway := range page.Value {
 			resources, err := ListNetworkVpnGatewayVpnConnections(ctx, connClient, vpnGateway)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *vpnGateway.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, resource := range resources {
@@ -1097,8 +1154,8 @@ func getNetworkVpnGatewaysVpnConnections(ctx context.Context, vpnGateway *armnet
 	return &resource
 }
 
-func NetworkVpnGatewaysVpnSites(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewVPNSitesClient(subscription, cred, nil)
+func NetworkVpnGatewaysVpnSites(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewVPNSitesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1136,8 +1193,8 @@ func getNetworkVpnGatewaysVpnSites(ctx context.Context, v *armnetwork.VPNSite) *
 	return &resource
 }
 
-func PublicIPAddress(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewPublicIPAddressesClient(subscription, cred, nil)
+func PublicIPAddress(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewPublicIPAddressesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1197,8 +1254,8 @@ func getPublicIPAddress(ctx context.Context, resourceGroup armresources.Resource
 	return &resource
 }
 
-func PublicIPPrefix(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewPublicIPPrefixesClient(subscription, cred, nil)
+func PublicIPPrefix(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewPublicIPPrefixesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1259,8 +1316,8 @@ func getPublicIPPrefix(ctx context.Context, resourceGroup armresources.ResourceG
 	return &resource
 }
 
-func DNSZones(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armdns.NewClientFactory(subscription, cred, nil)
+func DNSZones(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armdns.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1304,8 +1361,8 @@ func GetDNSZone(ctx context.Context, dnsZone *armdns.Zone) *Resource {
 	return &resource
 }
 
-func DNSResolvers(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armdnsresolver.NewClientFactory(subscription, cred, nil)
+func DNSResolvers(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armdnsresolver.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1348,8 +1405,8 @@ func GetDNSResolver(ctx context.Context, dnsResolver *armdnsresolver.DNSResolver
 	return &resource
 }
 
-func TrafficManagerProfile(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armtrafficmanager.NewClientFactory(subscription, cred, nil)
+func TrafficManagerProfile(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armtrafficmanager.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1393,8 +1450,8 @@ func GetTrafficManagerProfile(ctx context.Context, profile *armtrafficmanager.Pr
 	return &resource
 }
 
-func PrivateDnsZones(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armprivatedns.NewClientFactory(subscription, cred, nil)
+func PrivateDnsZones(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armprivatedns.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1438,8 +1495,8 @@ func GetPrivateDnsZone(ctx context.Context, privateZone *armprivatedns.PrivateZo
 	return &resource
 }
 
-func PrivateEndpoints(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewPrivateEndpointsClient(subscription, cred, nil)
+func PrivateEndpoints(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewPrivateEndpointsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1500,8 +1557,8 @@ func GetPrivateEndpoint(ctx context.Context, resourceGroup armresources.Resource
 	return &resource
 }
 
-func NetworkBastionHosts(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewBastionHostsClient(subscription, cred, nil)
+func NetworkBastionHosts(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewBastionHostsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1544,8 +1601,8 @@ func GetBastionHost(ctx context.Context, v *armnetwork.BastionHost) *Resource {
 	return &resource
 }
 
-func NetworkConnections(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewVirtualNetworkGatewayConnectionsClient(subscription, cred, nil)
+func NetworkConnections(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewVirtualNetworkGatewayConnectionsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1607,8 +1664,8 @@ func GetNetworkConnection(ctx context.Context, resourceGroup armresources.Resour
 	return &resource
 }
 
-func NetworkVirtualHubs(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewVirtualHubsClient(subscription, cred, nil)
+func NetworkVirtualHubs(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewVirtualHubsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1651,8 +1708,8 @@ func GetNetworkVirtualHub(ctx context.Context, v *armnetwork.VirtualHub) *Resour
 	return &resource
 }
 
-func NetworkVirtualWans(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewVirtualWansClient(subscription, cred, nil)
+func NetworkVirtualWans(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewVirtualWansClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -1694,8 +1751,8 @@ func GetNetworkVirtualWan(ctx context.Context, v *armnetwork.VirtualWAN) *Resour
 	return &resource
 }
 
-func NetworkDDoSProtectionPlan(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armnetwork.NewDdosProtectionPlansClient(subscription, cred, nil)
+func NetworkDDoSProtectionPlan(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armnetwork.NewDdosProtectionPlansClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -2,15 +2,15 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/operationalinsights/armoperationalinsights/v2"
 	"strings"
 
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func OperationalInsightsWorkspaces(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := armoperationalinsights.NewWorkspacesClient(subscription, cred, nil)
+func OperationalInsightsWorkspaces(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := armoperationalinsights.NewWorkspacesClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
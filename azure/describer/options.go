@@ -0,0 +1,156 @@
+package describer
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opengovern/og-util/pkg/describe/enums"
+	"go.uber.org/zap"
+)
+
+// DescribeOptions are the parameters of a describe call of a ResourceDescriber.
+type DescribeOptions struct {
+	TenantID      string
+	Subscriptions []string
+	TriggerType   enums.DescribeTriggerType
+	// Cloud is the Azure cloud the subscriptions live in, PublicCloud if empty.
+	Cloud  Cloud
+	Logger *zap.Logger
+	// FanOut bounds the number of subscriptions described in parallel.
+	FanOut SubscriptionFanOut
+
+	// TimeWindow overrides the window of the cost and metric describers, which otherwise derive it
+	// from the trigger type.
+	TimeWindow *TimeWindow
+	// MetricGranularity overrides the granularity of the monitoring metric describers.
+	MetricGranularity MetricGranularity
+	// ExcludeChildResources skips the child resources nested in the descriptions, e.g. the diagnostic
+	// settings of a key vault, by the describers supporting it.
+	ExcludeChildResources bool
+	// MaxItems stops the describe call once that many resources were described, 0 means no limit.
+	MaxItems int
+	// ResourceGroups only keeps the resources of these resource groups, all of them if empty.
+	// The describers supporting it list the resource groups instead of the whole subscription.
+	ResourceGroups []string
+	// Tags only keeps the resources matching the selector.
+	Tags TagSelector
+}
+
+type TimeWindow struct {
+	From time.Time
+	To   time.Time
+}
+
+type MetricGranularity string
+
+const (
+	MetricGranularityDaily       MetricGranularity = "DAILY"
+	MetricGranularityHourly      MetricGranularity = "HOURLY"
+	MetricGranularityFiveMinutes MetricGranularity = "FIVE_MINUTES"
+)
+
+// ErrMaxItemsReached is returned by the stream of a ResultLimiter once MaxItems resources were sent,
+// describers return it as is and their caller treats it as a successful end of the describe call.
+var ErrMaxItemsReached = errors.New("max items reached")
+
+// ResultLimiter applies the ResourceGroups, Tags and MaxItems options to the resources of a describe call.
+// It is shared by the subscriptions of the call.
+type ResultLimiter struct {
+	resourceGroups map[string]bool
+	tags           TagSelector
+	maxItems       int
+
+	mu    sync.Mutex
+	count int
+}
+
+func NewResultLimiter(opts DescribeOptions) *ResultLimiter {
+	l := &ResultLimiter{maxItems: opts.MaxItems, tags: opts.Tags}
+	if len(opts.ResourceGroups) > 0 {
+		l.resourceGroups = map[string]bool{}
+		for _, rg := range opts.ResourceGroups {
+			l.resourceGroups[strings.ToLower(rg)] = true
+		}
+	}
+	return l
+}
+
+// Done reports whether MaxItems resources were already described.
+func (l *ResultLimiter) Done() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.maxItems > 0 && l.count >= l.maxItems
+}
+
+// Stream wraps stream to drop the filtered out resources and to fail with ErrMaxItemsReached
+// once MaxItems resources were sent.
+func (l *ResultLimiter) Stream(stream *StreamSender) *StreamSender {
+	if stream == nil || (l.resourceGroups == nil && len(l.tags) == 0 && l.maxItems == 0) {
+		return stream
+	}
+
+	f := StreamSender(func(resource Resource) error {
+		if !l.keep(resource) {
+			return nil
+		}
+		if !l.take() {
+			return ErrMaxItemsReached
+		}
+		return (*stream)(resource)
+	})
+	return &f
+}
+
+// Filter drops the filtered out resources of values and the ones above MaxItems.
+func (l *ResultLimiter) Filter(values []Resource) []Resource {
+	if l.resourceGroups == nil && len(l.tags) == 0 && l.maxItems == 0 {
+		return values
+	}
+
+	var kept []Resource
+	for _, resource := range values {
+		if !l.keep(resource) {
+			continue
+		}
+		if !l.take() {
+			break
+		}
+		kept = append(kept, resource)
+	}
+	return kept
+}
+
+func (l *ResultLimiter) keep(resource Resource) bool {
+	if l.resourceGroups != nil && !l.resourceGroups[strings.ToLower(resourceGroupOf(resource))] {
+		return false
+	}
+	if len(l.tags) > 0 && !l.tags.Matches(tagsOf(resource.Description)) {
+		return false
+	}
+	return true
+}
+
+func (l *ResultLimiter) take() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.maxItems > 0 && l.count >= l.maxItems {
+		return false
+	}
+	l.count++
+	return true
+}
+
+func resourceGroupOf(resource Resource) string {
+	if resource.ResourceGroup != "" {
+		return resource.ResourceGroup
+	}
+	parts := strings.Split(resource.ID, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if strings.EqualFold(parts[i], "resourceGroups") {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
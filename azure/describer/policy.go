@@ -2,20 +2,20 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armpolicy"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func PolicyAssignment(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armpolicy.NewClientFactory(subscription, cred, nil)
+func PolicyAssignment(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armpolicy.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	client := clientFactory.NewAssignmentsClient()
 
-	resourceClient, err := armresources.NewClient(subscription, cred, nil)
+	resourceClient, err := armresources.NewClient(subscription, cred, ClientOptions(ctx))
 
 	pager := client.NewListPager(nil)
 	var values []Resource
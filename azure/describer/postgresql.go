@@ -4,15 +4,15 @@ import (
 	"context"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/postgresql/armpostgresql"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/postgresql/armpostgresqlflexibleservers"
 
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func PostgresqlServer(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armpostgresql.NewClientFactory(subscription, cred, nil)
+func PostgresqlServer(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armpostgresql.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -120,14 +120,14 @@ func GetPostgresqlServer(ctx context.Context, firewallClient *armpostgresql.Fire
 	return &resource, nil
 }
 
-func PostgresqlFlexibleservers(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+func PostgresqlFlexibleservers(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
 
-	client, err := armpostgresqlflexibleservers.NewServersClient(subscription, cred, nil)
+	client, err := armpostgresqlflexibleservers.NewServersClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 
-	configurationsClient, err := armpostgresqlflexibleservers.NewConfigurationsClient(subscription, cred, nil)
+	configurationsClient, err := armpostgresqlflexibleservers.NewConfigurationsClient(subscription, cred, ClientOptions(ctx))
 
 	pager := client.NewListPager(nil)
 	var values []Resource
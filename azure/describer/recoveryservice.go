@@ -2,7 +2,7 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/recoveryservices/armrecoveryservices"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/recoveryservices/armrecoveryservicesbackup/v3"
@@ -12,14 +12,14 @@ import (
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func RecoveryServicesVault(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armrecoveryservices.NewClientFactory(subscription, cred, nil)
+func RecoveryServicesVault(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armrecoveryservices.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	client := clientFactory.NewVaultsClient()
 
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -77,14 +77,14 @@ func GetRecoveryServicesVault(ctx context.Context, diagnosticClient *armmonitor.
 	return &resource, nil
 }
 
-func RecoveryServicesBackupJobs(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	vaultClientFactory, err := armrecoveryservices.NewClientFactory(subscription, cred, nil)
+func RecoveryServicesBackupJobs(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	vaultClientFactory, err := armrecoveryservices.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	vaultClient := vaultClientFactory.NewVaultsClient()
 
-	clientFactory, err := armrecoveryservicesbackup.NewClientFactory(subscription, cred, nil)
+	clientFactory, err := armrecoveryservicesbackup.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -214,14 +214,14 @@ func backupJobProperties(data *armrecoveryservicesbackup.JobResource) (map[strin
 	return output, nil
 }
 
-func RecoveryServicesBackupPolicies(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	vaultClientFactory, err := armrecoveryservices.NewClientFactory(subscription, cred, nil)
+func RecoveryServicesBackupPolicies(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	vaultClientFactory, err := armrecoveryservices.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	vaultClient := vaultClientFactory.NewVaultsClient()
 
-	clientFactory, err := armrecoveryservicesbackup.NewClientFactory(subscription, cred, nil)
+	clientFactory, err := armrecoveryservicesbackup.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -304,14 +304,14 @@ func GetRecoveryServicesBackupPolicy(policy *armrecoveryservicesbackup.Protectio
 	}
 }
 
-func RecoveryServicesBackupItem(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	vaultClientFactory, err := armrecoveryservices.NewClientFactory(subscription, cred, nil)
+func RecoveryServicesBackupItem(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	vaultClientFactory, err := armrecoveryservices.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	vaultClient := vaultClientFactory.NewVaultsClient()
 
-	clientFactory, err := armrecoveryservicesbackup.NewClientFactory(subscription, cred, nil)
+	clientFactory, err := armrecoveryservicesbackup.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
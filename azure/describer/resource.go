@@ -1,7 +1,30 @@
 package describer
 
+import (
+	"reflect"
+	"strings"
+
+	"github.com/opengovern/og-azure-describer/azure/model"
+)
+
+// StreamSender receives the described resources one by one when set, the describers then return no values. Its
+// errors, e.g. ErrMaxItemsReached, stop the describer and are returned as is.
 type StreamSender func(Resource) error
 
+// streamError wraps the errors of a StreamSender returned by the helpers also describing sub-scopes, so that
+// they are not reported as a failure of the scope.
+type streamError struct {
+	err error
+}
+
+func (e streamError) Error() string {
+	return e.err.Error()
+}
+
+func (e streamError) Unwrap() error {
+	return e.err
+}
+
 type Resource struct {
 	ID          string
 	Description interface{}
@@ -14,6 +37,57 @@ type Resource struct {
 	TenantID       string
 }
 
+// UniqueID is the deterministic identifier of the resource across describe jobs:
+//   - ARM IDs (/subscriptions/..., /providers/..., /tenants/...) are lower-cased, ARM being case-insensitive.
+//   - Composite IDs joining several IDs with "|", e.g. principal|role assignment of UserEffectiveAccess, are
+//     normalised part by part.
+//   - Monitoring metric rows, several per resource, get the timestamp of their data point as a "@" suffix.
+//   - The other IDs (Entra object IDs, cost rows, ...) are kept as is.
+//
+// It is the ID of the documents in the sinks and of the resources delivered with the job results. Changing it
+// re-keys the stored documents: the ones stored under a previous ID (mixed-case ARM IDs, metric rows without
+// timestamp) are not overwritten and have to be deleted by the ingestion side, e.g. by removing the documents
+// of a resource type missing from the described resource IDs of its next job.
 func (r Resource) UniqueID() string {
-	return r.ID
+	parts := strings.Split(r.ID, "|")
+	for i, part := range parts {
+		if isARMID(part) {
+			parts[i] = strings.ToLower(part)
+		}
+	}
+	id := strings.Join(parts, "|")
+
+	if metric := monitoringMetricOf(r.Description); metric != nil && metric.TimeStamp != "" {
+		id += "@" + metric.TimeStamp
+	}
+	return id
+}
+
+func isARMID(id string) bool {
+	lower := strings.ToLower(id)
+	return strings.HasPrefix(lower, "/subscriptions/") || strings.HasPrefix(lower, "/providers/") || strings.HasPrefix(lower, "/tenants/")
+}
+
+var monitoringMetricType = reflect.TypeOf(model.MonitoringMetric{})
+
+// monitoringMetricOf returns the MonitoringMetric embedded in a metric description, nil for the other descriptions.
+func monitoringMetricOf(description interface{}) *model.MonitoringMetric {
+	if m, ok := description.(JSONAllFieldsMarshaller); ok {
+		description = m.Value
+	}
+	v := reflect.ValueOf(description)
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return nil
+	}
+	if f := v.FieldByName("MonitoringMetric"); f.IsValid() && f.Type() == monitoringMetricType {
+		metric := f.Interface().(model.MonitoringMetric)
+		return &metric
+	}
+	return nil
 }
@@ -4,16 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/opengovern/og-util/pkg/describe/enums"
-
-	hamiltonAuth "github.com/manicminer/hamilton/auth"
 )
 
 const SubscriptionBatchSize = 100
@@ -23,11 +19,13 @@ type GenericResourceGraph struct {
 	Type  string
 }
 
-func (d GenericResourceGraph) DescribeResources(ctx context.Context, cred *azidentity.ClientSecretCredential, _ hamiltonAuth.Authorizer, tempSubscriptions []string, tenantId string, triggerType enums.DescribeTriggerType, stream *StreamSender) ([]Resource, error) {
-	ctx = WithTriggerType(ctx, triggerType)
-	query := fmt.Sprintf("%s | where type == \"%s\"", d.Table, strings.ToLower(d.Type))
+func (d GenericResourceGraph) DescribeResources(ctx context.Context, cred azcore.TokenCredential, opts DescribeOptions, stream *StreamSender) ([]Resource, error) {
+	ctx = WithDescribeOptions(ctx, opts)
+	limiter := NewResultLimiter(opts)
+	stream = limiter.Stream(stream)
+	query := fmt.Sprintf("%s | where type == \"%s\"", d.Table, strings.ToLower(d.Type)) + resourceGraphScopeFilter(opts)
 
-	client, err := armresourcegraph.NewClient(cred, nil)
+	client, err := armresourcegraph.NewClient(cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +33,7 @@ func (d GenericResourceGraph) DescribeResources(ctx context.Context, cred *azide
 	var values []Resource
 
 	var subscriptions []*string
-	for _, subscription := range tempSubscriptions {
+	for _, subscription := range opts.Subscriptions {
 		subscriptions = append(subscriptions, &subscription)
 	}
 
@@ -65,16 +63,6 @@ func (d GenericResourceGraph) DescribeResources(ctx context.Context, cred *azide
 				return nil, err
 			}
 
-			// No Need to wait for quota
-			//
-			//quotaRemaining, untilResets, err := quota()
-			//if err != nil {
-			//	return nil, err
-			//}
-			//if quotaRemaining == 0 {
-			//	time.Sleep(untilResets)
-			//}
-
 			for _, v := range response.Data.([]interface{}) {
 				m := v.(map[string]interface{})
 				loc := "global"
@@ -90,13 +78,17 @@ func (d GenericResourceGraph) DescribeResources(ctx context.Context, cred *azide
 				}
 				if stream != nil {
 					if err := (*stream)(resource); err != nil {
+						if errors.Is(err, ErrMaxItemsReached) {
+							return values, nil
+						}
 						return nil, err
 					}
 				} else {
-					values = append(values, resource)
+					values = append(values, limiter.Filter([]Resource{resource})...)
 				}
-
-				values = append(values)
+			}
+			if limiter.Done() {
+				return values, nil
 			}
 			first, skipToken = false, response.SkipToken
 		}
@@ -105,7 +97,7 @@ func (d GenericResourceGraph) DescribeResources(ctx context.Context, cred *azide
 	return values, nil
 }
 
-// quota parses the Azure throttling headers.
+// quota parses the Azure throttling headers, the Throttler pauses the queries until the quota resets.
 // See https://docs.microsoft.com/en-us/azure/governance/resource-graph/concepts/guidance-for-throttled-requests#understand-throttling-headers
 func quota(header http.Header) (int, time.Duration, error) {
 	remainingHeader := header[http.CanonicalHeaderKey("x-ms-user-quota-remaining")]
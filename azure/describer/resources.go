@@ -4,14 +4,14 @@ import (
 	"context"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func listResourceGroups(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string) ([]armresources.ResourceGroup, error) {
-	clientFactory, err := armresources.NewClientFactory(subscription, cred, nil)
+func listResourceGroups(ctx context.Context, cred azcore.TokenCredential, subscription string) ([]armresources.ResourceGroup, error) {
+	clientFactory, err := armresources.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -30,8 +30,8 @@ func listResourceGroups(ctx context.Context, cred *azidentity.ClientSecretCreden
 	return values, nil
 }
 
-func ResourceProvider(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armresources.NewClientFactory(subscription, cred, nil)
+func ResourceProvider(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armresources.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -72,8 +72,8 @@ func GetResourceProvider(ctx context.Context, provider *armresources.Provider) *
 	return &resource
 }
 
-func ResourceGroup(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armresources.NewClientFactory(subscription, cred, nil)
+func ResourceGroup(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armresources.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -115,9 +115,9 @@ func GetResourceGroup(ctx context.Context, group *armresources.ResourceGroup) *R
 	return &resource
 }
 
-func Resources(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+func Resources(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
 
-	clientFactory, err := armresources.NewClientFactory(subscription, cred, nil)
+	clientFactory, err := armresources.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
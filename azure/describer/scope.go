@@ -0,0 +1,118 @@
+package describer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TagSelector matches the resources having all its tags. Tag names are case-insensitive,
+// an empty value matches any value of the tag.
+type TagSelector map[string]string
+
+func (s TagSelector) Matches(tags map[string]string) bool {
+	for key, value := range s {
+		found := false
+		for k, v := range tags {
+			if strings.EqualFold(k, key) && (value == "" || v == value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// InScope reports whether the resource with the given ID and ARM tags is in the resource groups and
+// matches the tag selector of the describe call. Describers use it to skip the resources before fetching
+// their details.
+func InScope(ctx context.Context, id string, tags map[string]*string) bool {
+	opts := GetDescribeOptionsFromContext(ctx)
+	if len(opts.ResourceGroups) > 0 && !containsFold(opts.ResourceGroups, resourceGroupOf(Resource{ID: id})) {
+		return false
+	}
+	if len(opts.Tags) > 0 {
+		values := map[string]string{}
+		for k, v := range tags {
+			if v != nil {
+				values[k] = *v
+			}
+		}
+		return opts.Tags.Matches(values)
+	}
+	return true
+}
+
+// isResourceGroupNotFound reports whether err is returned for a resource group missing from the subscription,
+// which is expected when a describe call scoped to resource groups spans several subscriptions.
+func isResourceGroupNotFound(err error) bool {
+	return ClassifyError(err).Code == "ResourceGroupNotFound"
+}
+
+// resourceGroupID returns the ARM ID of a resource group, e.g. to report it as a failed scope.
+func resourceGroupID(subscription, resourceGroup string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", subscription, resourceGroup)
+}
+
+// resourceGraphScopeFilter returns the Resource Graph clauses filtering the resource groups and tag selector of opts.
+func resourceGraphScopeFilter(opts DescribeOptions) string {
+	var filter string
+	if len(opts.ResourceGroups) > 0 {
+		var rgs []string
+		for _, rg := range opts.ResourceGroups {
+			rgs = append(rgs, kqlString(rg))
+		}
+		filter += fmt.Sprintf(" | where resourceGroup in~ (%s)", strings.Join(rgs, ", "))
+	}
+
+	var keys []string
+	for key := range opts.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	// tags[key] is case-sensitive, the tag names are matched with =~ like in TagSelector.Matches. Azure tag
+	// names are unique regardless of their case so the expansion yields a row per matching resource.
+	for _, key := range keys {
+		filter += fmt.Sprintf(" | mv-expand tagName = bag_keys(tags) to typeof(string) | where tagName =~ %s", kqlString(key))
+		if value := opts.Tags[key]; value != "" {
+			filter += fmt.Sprintf(" and tostring(tags[tagName]) == %s", kqlString(value))
+		}
+		filter += " | project-away tagName"
+	}
+	return filter
+}
+
+func kqlString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// tagsOf returns the tags of a resource description: the "tags" key of a Resource Graph row or
+// the Tags field of the ARM model of the described resource, see descriptionTagsFields.
+func tagsOf(description interface{}) map[string]string {
+	if m, ok := description.(JSONAllFieldsMarshaller); ok {
+		description = m.Value
+	}
+	if row, ok := description.(map[string]interface{}); ok {
+		tags := map[string]string{}
+		if rowTags, ok := row["tags"].(map[string]interface{}); ok {
+			for k, v := range rowTags {
+				tags[k] = fmt.Sprint(v)
+			}
+		}
+		return tags
+	}
+	return tagsOfDescription(description)
+}
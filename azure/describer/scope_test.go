@@ -0,0 +1,76 @@
+package describer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
+	"github.com/opengovern/og-azure-describer/azure/model"
+)
+
+func TestScope(t *testing.T) {
+	env := "prod"
+	description := JSONAllFieldsMarshaller{
+		Value: model.KeyVaultDescription{
+			Resource: armkeyvault.Resource{Tags: map[string]*string{"Env": &env}},
+		},
+	}
+
+	tags := tagsOf(description)
+	if tags["Env"] != "prod" {
+		t.Fatalf("unexpected tags: %v", tags)
+	}
+	if !(TagSelector{"env": "prod"}).Matches(tags) || !(TagSelector{"env": ""}).Matches(tags) {
+		t.Error("expected the selector to match")
+	}
+	if (TagSelector{"env": "dev"}).Matches(tags) || (TagSelector{"owner": ""}).Matches(tags) {
+		t.Error("expected the selector not to match")
+	}
+
+	vault := "vault"
+	certificate := JSONAllFieldsMarshaller{
+		Value: model.KeyVaultCertificateDescription{
+			Vault: armkeyvault.Resource{Tags: map[string]*string{"Env": &env, "Owner": &vault}},
+		},
+	}
+	if tags := tagsOf(certificate); len(tags) != 0 {
+		t.Errorf("expected the certificate not to have the tags of its vault, got %v", tags)
+	}
+	key := &model.KeyVaultKeyDescription{
+		Vault: armkeyvault.Resource{Tags: map[string]*string{"Owner": &vault}},
+		Key:   armkeyvault.Key{Tags: map[string]*string{"Env": &env}},
+	}
+	if tags := tagsOf(key); len(tags) != 1 || tags["Env"] != "prod" {
+		t.Errorf("expected the tags of the key, got %v", tags)
+	}
+
+	filter := resourceGraphScopeFilter(DescribeOptions{
+		ResourceGroups: []string{"rg-a", "rg'b"},
+		Tags:           TagSelector{"env": "prod", "owner": ""},
+	})
+	want := ` | where resourceGroup in~ ('rg-a', 'rg\'b')` +
+		` | mv-expand tagName = bag_keys(tags) to typeof(string) | where tagName =~ 'env' and tostring(tags[tagName]) == 'prod' | project-away tagName` +
+		` | mv-expand tagName = bag_keys(tags) to typeof(string) | where tagName =~ 'owner' | project-away tagName`
+	if filter != want {
+		t.Errorf("filter:\ngot  %s\nwant %s", filter, want)
+	}
+}
+
+func TestDescriptionTagsFields(t *testing.T) {
+	for typ, field := range descriptionTagsFields {
+		if field != "" {
+			f, ok := typ.FieldByName(field)
+			if !ok {
+				t.Errorf("%s has no field %s", typ.Name(), field)
+				continue
+			}
+			typ = f.Type
+			for typ.Kind() == reflect.Ptr {
+				typ = typ.Elem()
+			}
+		}
+		if tags, ok := typ.FieldByName("Tags"); !ok || tags.Type.Kind() != reflect.Map || tags.Type.Key().Kind() != reflect.String {
+			t.Errorf("%s has no Tags", typ.Name())
+		}
+	}
+}
@@ -2,15 +2,15 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/security/armsecurity"
 	"strings"
 
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func SecurityCenterAutoProvisioning(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsecurity.NewClientFactory(subscription, cred, nil)
+func SecurityCenterAutoProvisioning(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsecurity.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -52,8 +52,8 @@ func GetSecurityCenterAutoProvisioning(ctx context.Context, v *armsecurity.AutoP
 	return &resource
 }
 
-func SecurityCenterContact(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsecurity.NewClientFactory(subscription, cred, nil)
+func SecurityCenterContact(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsecurity.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -94,8 +94,8 @@ func GetSecurityCenterContact(ctx context.Context, v *armsecurity.Contact) *Reso
 	return &resource
 }
 
-func SecurityCenterJitNetworkAccessPolicy(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsecurity.NewClientFactory(subscription, cred, nil)
+func SecurityCenterJitNetworkAccessPolicy(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsecurity.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -136,8 +136,8 @@ func GetSecurityCenterJitNetworkAccessPolicy(ctx context.Context, v *armsecurity
 	return &resource
 }
 
-func SecurityCenterSetting(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsecurity.NewClientFactory(subscription, cred, nil)
+func SecurityCenterSetting(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsecurity.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -185,8 +185,8 @@ func GetSecurityCenterSetting(ctx context.Context, v armsecurity.SettingClassifi
 	return &resource
 }
 
-func SecurityCenterSubscriptionPricing(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsecurity.NewClientFactory(subscription, cred, nil)
+func SecurityCenterSubscriptionPricing(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsecurity.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -224,8 +224,8 @@ func GetSecurityCenterSubscriptionPricing(ctx context.Context, v *armsecurity.Pr
 	return &resource
 }
 
-func SecurityCenterAutomation(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsecurity.NewClientFactory(subscription, cred, nil)
+func SecurityCenterAutomation(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsecurity.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -268,8 +268,8 @@ func GetSecurityCenterAutomation(ctx context.Context, v *armsecurity.Automation)
 	return &resource
 }
 
-func SecurityCenterSubAssessment(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsecurity.NewClientFactory(subscription, cred, nil)
+func SecurityCenterSubAssessment(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsecurity.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/servicebus/armservicebus"
@@ -12,13 +12,13 @@ import (
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func ServiceBusQueue(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+func ServiceBusQueue(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
 	rgs, err := listResourceGroups(ctx, cred, subscription)
 	if err != nil {
 		return nil, err
 	}
 
-	clientFactory, err := armservicebus.NewClientFactory(subscription, cred, nil)
+	clientFactory, err := armservicebus.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +43,7 @@ func ServiceBusQueue(ctx context.Context, cred *azidentity.ClientSecretCredentia
 	return values, nil
 }
 
-func ListResourceGroupServiceBusQueue(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, client *armservicebus.QueuesClient, rg armresources.ResourceGroup) ([]Resource, error) {
+func ListResourceGroupServiceBusQueue(ctx context.Context, cred azcore.TokenCredential, subscription string, client *armservicebus.QueuesClient, rg armresources.ResourceGroup) ([]Resource, error) {
 	ns, err := serviceBusNamespace(ctx, cred, subscription, *rg.Name)
 	if err != nil {
 		return nil, err
@@ -86,13 +86,13 @@ func GetServiceBusQueue(ctx context.Context, v *armservicebus.SBQueue) *Resource
 	return &resource
 }
 
-func ServiceBusTopic(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+func ServiceBusTopic(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
 	rgs, err := listResourceGroups(ctx, cred, subscription)
 	if err != nil {
 		return nil, err
 	}
 
-	clientFactory, err := armservicebus.NewClientFactory(subscription, cred, nil)
+	clientFactory, err := armservicebus.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -117,7 +117,7 @@ func ServiceBusTopic(ctx context.Context, cred *azidentity.ClientSecretCredentia
 	return values, nil
 }
 
-func ListResourceGroupServiceBusTopic(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, client *armservicebus.TopicsClient, rg armresources.ResourceGroup) ([]Resource, error) {
+func ListResourceGroupServiceBusTopic(ctx context.Context, cred azcore.TokenCredential, subscription string, client *armservicebus.TopicsClient, rg armresources.ResourceGroup) ([]Resource, error) {
 	ns, err := serviceBusNamespace(ctx, cred, subscription, *rg.Name)
 	if err != nil {
 		return nil, err
@@ -160,8 +160,8 @@ func GetServiceBusTopic(ctx context.Context, v *armservicebus.SBTopic) *Resource
 	return &resource
 }
 
-func serviceBusNamespace(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, resourceGroup string) ([]*armservicebus.SBNamespace, error) {
-	clientFactory, err := armservicebus.NewClientFactory(subscription, cred, nil)
+func serviceBusNamespace(ctx context.Context, cred azcore.TokenCredential, subscription string, resourceGroup string) ([]*armservicebus.SBNamespace, error) {
+	clientFactory, err := armservicebus.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -179,8 +179,8 @@ func serviceBusNamespace(ctx context.Context, cred *azidentity.ClientSecretCrede
 	return values, nil
 }
 
-func ServicebusNamespace(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armservicebus.NewClientFactory(subscription, cred, nil)
+func ServicebusNamespace(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armservicebus.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -188,7 +188,7 @@ func ServicebusNamespace(ctx context.Context, cred *azidentity.ClientSecretCrede
 	namespaceClient := clientFactory.NewNamespacesClient()
 	client := clientFactory.NewNamespacesClient()
 
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -3,7 +3,7 @@ package describer
 import (
 	"context"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
 
 	"strings"
@@ -11,8 +11,8 @@ import (
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func MssqlManagedInstance(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsql.NewClientFactory(subscription, cred, nil)
+func MssqlManagedInstance(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsql.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -97,8 +97,8 @@ func GetMssqlManagedInstance(ctx context.Context, managedInstanceClient *armsql.
 	return &resource, nil
 }
 
-func MssqlManagedInstanceDatabases(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsql.NewClientFactory(subscription, cred, nil)
+func MssqlManagedInstanceDatabases(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsql.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -115,6 +115,9 @@ func MssqlManagedInstanceDatabases(ctx context.Context, cred *azidentity.ClientS
 		for _, managedInstance := range page.Value {
 			resources, err := ListManagedInstanceDatabases(ctx, dbClient, managedInstance)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *managedInstance.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			if stream != nil {
@@ -167,8 +170,8 @@ func GetManagedInstanceDatabases(ctx context.Context, managedInstance *armsql.Ma
 	return &resource
 }
 
-func SqlDatabase(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsql.NewClientFactory(subscription, cred, nil)
+func SqlDatabase(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsql.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -193,6 +196,9 @@ func SqlDatabase(ctx context.Context, cred *azidentity.ClientSecretCredential, s
 		for _, server := range page.Value {
 			resources, err := ListServerSqlDatabases(ctx, recoverableClient, advisorsClient, databaseVulnerabilityScanClient, databaseVulnerabilityClient, transparentDataClient, longTermClient, databasesClientClient, auditingPolicyClient, client, server)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *server.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, resource := range resources {
@@ -322,8 +328,8 @@ func GetSqlDatabase(ctx context.Context, recoverableClient *armsql.RecoverableDa
 	return &resource, nil
 }
 
-func SqlInstancePool(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsql.NewClientFactory(subscription, cred, nil)
+func SqlInstancePool(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsql.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
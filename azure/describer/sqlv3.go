@@ -2,7 +2,8 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/mysql/armmysqlflexibleservers"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sqlvirtualmachine/armsqlvirtualmachine"
@@ -11,8 +12,8 @@ import (
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func SqlServer(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsql.NewClientFactory(subscription, cred, nil)
+func SqlServer(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsql.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -28,28 +29,70 @@ func SqlServer(ctx context.Context, cred *azidentity.ClientSecretCredential, sub
 	automaticTuningClient := clientFactory.NewServerAutomaticTuningClient()
 	client := clientFactory.NewServersClient()
 
-	pager := client.NewListPager(nil)
 	var values []Resource
-	for pager.More() {
-		page, err := pager.NextPage(ctx)
-		if err != nil {
-			return nil, err
-		}
-		for _, server := range page.Value {
+	describe := func(servers []*armsql.Server) error {
+		for _, server := range servers {
+			if !InScope(ctx, *server.ID, server.Tags) {
+				continue
+			}
 			resource, err := GetSqlServer(ctx, automaticTuningClient, failoverClient, virtualNetworkClient, privateEndpointClient, encryptionProtectorsClient, firewallRulesClient, serverVulnerabilityClient, serverAzureClient, serverSecurityClient, serverBlobClient, server)
 			if err != nil {
-				return nil, err
+				return err
 			}
 			if stream != nil {
 				if err := (*stream)(*resource); err != nil {
-					return nil, err
+					return err
 				}
 			} else {
 				values = append(values, *resource)
 			}
 		}
+		return nil
 	}
-	return values, err
+
+	if resourceGroups := GetResourceGroupsFromContext(ctx); len(resourceGroups) > 0 {
+		for _, resourceGroup := range resourceGroups {
+			pager := client.NewListByResourceGroupPager(resourceGroup, nil)
+			for pager.More() {
+				page, err := pager.NextPage(ctx)
+				if err != nil {
+					if isResourceGroupNotFound(err) || ReportScopeFailure(ctx, ScopeTypeResourceGroup, resourceGroupID(subscription, resourceGroup), err) {
+						break
+					}
+					return nil, err
+				}
+				if err := describe(page.Value); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return values, nil
+	}
+
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := describe(page.Value); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func SqlServerByID(ctx context.Context, cred azcore.TokenCredential, id *arm.ResourceID) (*Resource, error) {
+	clientFactory, err := armsql.NewClientFactory(id.SubscriptionID, cred, ClientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	server, err := clientFactory.NewServersClient().Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return GetSqlServer(ctx, clientFactory.NewServerAutomaticTuningClient(), clientFactory.NewFailoverGroupsClient(), clientFactory.NewVirtualNetworkRulesClient(), clientFactory.NewPrivateEndpointConnectionsClient(), clientFactory.NewEncryptionProtectorsClient(), clientFactory.NewFirewallRulesClient(), clientFactory.NewServerVulnerabilityAssessmentsClient(), clientFactory.NewServerAzureADAdministratorsClient(), clientFactory.NewServerSecurityAlertPoliciesClient(), clientFactory.NewServerBlobAuditingPoliciesClient(), &server.Server)
 }
 
 func GetSqlServer(ctx context.Context, automaticTuningClient *armsql.ServerAutomaticTuningClient, failoverClient *armsql.FailoverGroupsClient, virtualNetworkClient *armsql.VirtualNetworkRulesClient, privateEndpointClient *armsql.PrivateEndpointConnectionsClient, encryptionProtectorsClient *armsql.EncryptionProtectorsClient, firewallRulesClient *armsql.FirewallRulesClient, serverVulnerabilityClient *armsql.ServerVulnerabilityAssessmentsClient, serverAzureClient *armsql.ServerAzureADAdministratorsClient, serverSecurityClient *armsql.ServerSecurityAlertPoliciesClient, serverBlobClient *armsql.ServerBlobAuditingPoliciesClient, server *armsql.Server) (*Resource, error) {
@@ -174,8 +217,8 @@ func GetSqlServer(ctx context.Context, automaticTuningClient *armsql.ServerAutom
 	return &resource, nil
 }
 
-func SqlServerJobAgents(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsql.NewClientFactory(subscription, cred, nil)
+func SqlServerJobAgents(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsql.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -193,6 +236,9 @@ func SqlServerJobAgents(ctx context.Context, cred *azidentity.ClientSecretCreden
 		for _, server := range page.Value {
 			resources, err := ListSqlServerJobAgents(ctx, client, server)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *server.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, resource := range resources {
@@ -245,8 +291,8 @@ func GetSqlServerJobAgent(ctx context.Context, server *armsql.Server, job *armsq
 	return &resource
 }
 
-func SqlVirtualClusters(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsql.NewClientFactory(subscription, cred, nil)
+func SqlVirtualClusters(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsql.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -292,8 +338,8 @@ func GetSqlVirtualCluster(ctx context.Context, v *armsql.VirtualCluster) *Resour
 	return &resource
 }
 
-func SqlServerElasticPool(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsql.NewClientFactory(subscription, cred, nil)
+func SqlServerElasticPool(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsql.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -403,8 +449,8 @@ func GetSqlServerElasticPool(ctx context.Context, server *armsql.Server, activit
 	return &resource, nil
 }
 
-func SqlServerVirtualMachine(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsqlvirtualmachine.NewClientFactory(subscription, cred, nil)
+func SqlServerVirtualMachine(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsqlvirtualmachine.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -447,8 +493,8 @@ func GetSqlServerVirtualMachine(ctx context.Context, vm *armsqlvirtualmachine.SQ
 	return &resource
 }
 
-func SqlServerVirtualMachineGroups(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsqlvirtualmachine.NewClientFactory(subscription, cred, nil)
+func SqlServerVirtualMachineGroups(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsqlvirtualmachine.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -492,8 +538,8 @@ func GetSqlServerVirtualMachineGroups(ctx context.Context, vm *armsqlvirtualmach
 	return &resource
 }
 
-func SqlServerFlexibleServer(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armmysqlflexibleservers.NewClientFactory(subscription, cred, nil)
+func SqlServerFlexibleServer(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armmysqlflexibleservers.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
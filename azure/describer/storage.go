@@ -2,10 +2,11 @@ package describer
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/data/aztables"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
@@ -16,13 +17,14 @@ import (
 	"github.com/opengovern/og-util/pkg/concurrency"
 
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/opengovern/og-azure-describer/azure/model"
 	"github.com/tombuildsstuff/giovanni/storage/2018-11-09/queue/queues"
 	"github.com/tombuildsstuff/giovanni/storage/2019-12-12/blob/accounts"
 )
 
-func StorageContainer(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armstorage.NewClientFactory(subscription, cred, nil)
+func StorageContainer(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armstorage.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -42,6 +44,9 @@ func StorageContainer(ctx context.Context, cred *azidentity.ClientSecretCredenti
 			wpe.AddJob(func() (interface{}, error) {
 				results, err := ListAccountStorageContainers(ctx, client, account)
 				if err != nil {
+					if ReportScopeFailure(ctx, ScopeTypeResource, *account.ID, err) {
+						return nil, nil
+					}
 					return nil, err
 				}
 				return results, nil
@@ -52,7 +57,7 @@ func StorageContainer(ctx context.Context, cred *azidentity.ClientSecretCredenti
 	results := wpe.Run()
 	for _, r := range results {
 		if r.Error != nil {
-			return nil, err
+			return nil, r.Error
 		}
 		if r.Value == nil {
 			continue
@@ -116,16 +121,16 @@ func GetAccountStorageContainter(ctx context.Context, client *armstorage.BlobCon
 	}, nil
 }
 
-func StorageAccount(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+func StorageAccount(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
 
-	clientFactory, err := armstorage.NewClientFactory(subscription, cred, nil)
+	clientFactory, err := armstorage.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 
 	encryptionScopesStorageClient := clientFactory.NewEncryptionScopesClient()
 
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -139,30 +144,78 @@ func StorageAccount(ctx context.Context, cred *azidentity.ClientSecretCredential
 
 	storageClient := clientFactory.NewAccountsClient()
 
-	pager := storageClient.NewListPager(nil)
 	var values []Resource
-	for pager.More() {
-		page, err := pager.NextPage(ctx)
-		if err != nil {
-			return nil, err
-		}
-		for _, account := range page.Value {
+	describe := func(accounts []*armstorage.Account) error {
+		for _, account := range accounts {
+			if !InScope(ctx, *account.ID, account.Tags) {
+				continue
+			}
 			resource, err := GetStorageAccount(ctx, storageClient, encryptionScopesStorageClient, diagnosticClient, fileServicesStorageClient, blobServicesStorageClient, managementPoliciesStorageClient, account)
 			if err != nil {
-				return nil, err
+				return err
 			}
 			if stream != nil {
 				if err := (*stream)(*resource); err != nil {
-					return nil, err
+					return err
 				}
 			} else {
 				values = append(values, *resource)
 			}
 		}
+		return nil
+	}
+
+	if resourceGroups := GetResourceGroupsFromContext(ctx); len(resourceGroups) > 0 {
+		for _, resourceGroup := range resourceGroups {
+			pager := storageClient.NewListByResourceGroupPager(resourceGroup, nil)
+			for pager.More() {
+				page, err := pager.NextPage(ctx)
+				if err != nil {
+					if isResourceGroupNotFound(err) || ReportScopeFailure(ctx, ScopeTypeResourceGroup, resourceGroupID(subscription, resourceGroup), err) {
+						break
+					}
+					return nil, err
+				}
+				if err := describe(page.Value); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return values, nil
+	}
+
+	pager := storageClient.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := describe(page.Value); err != nil {
+			return nil, err
+		}
 	}
 	return values, nil
 }
 
+func StorageAccountByID(ctx context.Context, cred azcore.TokenCredential, id *arm.ResourceID) (*Resource, error) {
+	clientFactory, err := armstorage.NewClientFactory(id.SubscriptionID, cred, ClientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	monitorClientFactory, err := armmonitor.NewClientFactory(id.SubscriptionID, cred, ClientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	storageClient := clientFactory.NewAccountsClient()
+	account, err := storageClient.GetProperties(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return GetStorageAccount(ctx, storageClient, clientFactory.NewEncryptionScopesClient(), monitorClientFactory.NewDiagnosticSettingsClient(), clientFactory.NewFileServicesClient(), clientFactory.NewBlobServicesClient(), clientFactory.NewManagementPoliciesClient(), &account.Account)
+}
+
 func GetStorageAccount(ctx context.Context, storageClient *armstorage.AccountsClient, encryptionScopesStorageClient *armstorage.EncryptionScopesClient, diagnosticClient *armmonitor.DiagnosticSettingsClient, fileServicesStorageClient *armstorage.FileServicesClient, blobServicesStorageClient *armstorage.BlobServicesClient, managementPoliciesStorageClient *armstorage.ManagementPoliciesClient, account *armstorage.Account) (*Resource, error) {
 	resourceGroup := &strings.Split(*account.ID, "/")[4]
 
@@ -204,7 +257,7 @@ func GetStorageAccount(ctx context.Context, storageClient *armstorage.AccountsCl
 					return nil, err
 				}
 
-				client := accounts.New()
+				client := accounts.NewWithEnvironment(storageEnvironment(ctx))
 				client.Client.Authorizer = storageAuth
 
 				resp, err := client.GetServiceProperties(ctx, *account.Name)
@@ -231,23 +284,25 @@ func GetStorageAccount(ctx context.Context, storageClient *armstorage.AccountsCl
 	}
 
 	var diagSettingsOp []*armmonitor.DiagnosticSettingsResource
-	pager1 := diagnosticClient.NewListPager(*account.ID, nil)
-	for pager1.More() {
-		page1, err := pager1.NextPage(ctx)
-		if err != nil {
-			break
+	var vsop []*armstorage.EncryptionScope
+	if IncludeChildResources(ctx) {
+		pager1 := diagnosticClient.NewListPager(*account.ID, nil)
+		for pager1.More() {
+			page1, err := pager1.NextPage(ctx)
+			if err != nil {
+				break
+			}
+			diagSettingsOp = append(diagSettingsOp, page1.Value...)
 		}
-		diagSettingsOp = append(diagSettingsOp, page1.Value...)
-	}
 
-	var vsop []*armstorage.EncryptionScope
-	pager2 := encryptionScopesStorageClient.NewListPager(*resourceGroup, *account.Name, nil)
-	for pager2.More() {
-		page2, err := pager2.NextPage(ctx)
-		if err != nil {
-			break
+		pager2 := encryptionScopesStorageClient.NewListPager(*resourceGroup, *account.Name, nil)
+		for pager2.More() {
+			page2, err := pager2.NextPage(ctx)
+			if err != nil {
+				break
+			}
+			vsop = append(vsop, page2.Value...)
 		}
-		vsop = append(vsop, page2.Value...)
 	}
 
 	var storageProperties *queues.StorageServiceProperties
@@ -265,7 +320,7 @@ func GetStorageAccount(ctx context.Context, storageClient *armstorage.AccountsCl
 					return nil, err
 				}
 
-				queuesClient := queues.New()
+				queuesClient := queues.NewWithEnvironment(storageEnvironment(ctx))
 				queuesClient.Client.Authorizer = storageAuth
 
 				resp, err := queuesClient.GetServiceProperties(ctx, *account.Name)
@@ -290,7 +345,7 @@ func GetStorageAccount(ctx context.Context, storageClient *armstorage.AccountsCl
 	if *account.Kind != "FileStorage" {
 
 		for _, key := range v.Keys {
-			serviceUrl := "https://" + *account.Name + ".table.core.windows.net/"
+			serviceUrl := storageServiceEndpoint(ctx, account, "table")
 
 			auth, err := aztables.NewSharedKeyCredential(*account.Name, *key.Value)
 			if err != nil {
@@ -350,8 +405,8 @@ func GetStorageAccount(ctx context.Context, storageClient *armstorage.AccountsCl
 	return &resource, nil
 }
 
-func StorageBlob(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armstorage.NewClientFactory(subscription, cred, nil)
+func StorageBlob(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armstorage.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -366,25 +421,23 @@ func StorageBlob(ctx context.Context, cred *azidentity.ClientSecretCredential, s
 			return nil, err
 		}
 		for _, v := range page.Value {
-			resources, err := ListAccountStorageBlobs(ctx, containerClient, accountClient, v)
+			// the blobs are streamed one by one, an account can hold far too many of them to be buffered
+			resources, err := ListAccountStorageBlobs(ctx, containerClient, accountClient, v, stream)
 			if err != nil {
-				return nil, err
-			}
-			for _, resource := range resources {
-				if stream != nil {
-					if err := (*stream)(resource); err != nil {
-						return nil, err
-					}
-				} else {
-					values = append(values, resource)
+				if !errors.As(err, &streamError{}) && ReportScopeFailure(ctx, ScopeTypeResource, *v.ID, err) {
+					continue
 				}
+				return nil, err
 			}
+			values = append(values, resources...)
 		}
 	}
 	return values, nil
 }
 
-func ListAccountStorageBlobs(ctx context.Context, containerClient *armstorage.BlobContainersClient, accountClient *armstorage.AccountsClient, storageAccount *armstorage.Account) ([]Resource, error) {
+// ListAccountStorageBlobs lists the blobs of the storage account, they are sent to stream if it is set and
+// returned otherwise.
+func ListAccountStorageBlobs(ctx context.Context, containerClient *armstorage.BlobContainersClient, accountClient *armstorage.AccountsClient, storageAccount *armstorage.Account, stream *StreamSender) ([]Resource, error) {
 	if storageAccount == nil || storageAccount.ID == nil {
 		return nil, nil
 	}
@@ -406,7 +459,7 @@ func ListAccountStorageBlobs(ctx context.Context, containerClient *armstorage.Bl
 	if err != nil {
 		return nil, err
 	}
-	baseUrl := fmt.Sprintf("https://%s.blob.core.windows.net", storageAccountName)
+	baseUrl := storageServiceEndpoint(ctx, storageAccount, "blob")
 	blobClient, err := azblob.NewClientWithSharedKeyCredential(baseUrl, credential, nil)
 	if err != nil {
 		return nil, err
@@ -542,7 +595,13 @@ func ListAccountStorageBlobs(ctx context.Context, containerClient *armstorage.Bl
 							Value: desc,
 						},
 					}
-					values = append(values, resource)
+					if stream != nil {
+						if err := (*stream)(resource); err != nil {
+							return nil, streamError{err: err}
+						}
+					} else {
+						values = append(values, resource)
+					}
 				}
 			}
 		}
@@ -550,8 +609,8 @@ func ListAccountStorageBlobs(ctx context.Context, containerClient *armstorage.Bl
 	return values, nil
 }
 
-func StorageBlobService(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armstorage.NewClientFactory(subscription, cred, nil)
+func StorageBlobService(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armstorage.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -620,8 +679,8 @@ func GetStorageBlobService(ctx context.Context, account *armstorage.Account, res
 	return &resource
 }
 
-func StorageQueue(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armstorage.NewClientFactory(subscription, cred, nil)
+func StorageQueue(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armstorage.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -706,8 +765,8 @@ func GetStorageQueue(ctx context.Context, account *armstorage.Account, resourceG
 	return &resource
 }
 
-func StorageFileShare(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armstorage.NewClientFactory(subscription, cred, nil)
+func StorageFileShare(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armstorage.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -785,8 +844,8 @@ func GetStorageFileShares(ctx context.Context, account *armstorage.Account, reso
 	return &resource
 }
 
-func StorageTable(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armstorage.NewClientFactory(subscription, cred, nil)
+func StorageTable(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armstorage.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -873,8 +932,8 @@ func GetStorageTable(ctx context.Context, account *armstorage.Account, resourceG
 	return &resource
 }
 
-func StorageTableService(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armstorage.NewClientFactory(subscription, cred, nil)
+func StorageTableService(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armstorage.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -953,3 +1012,30 @@ func GetAccountStorageTableService(ctx context.Context, resourceGroup armresourc
 	}
 	return &resource
 }
+
+// storageServiceEndpoint returns the endpoint of a service of a storage account, the primary endpoint
+// reported by ARM if any, otherwise the one built from the storage suffix of the cloud of the context.
+func storageServiceEndpoint(ctx context.Context, account *armstorage.Account, service string) string {
+	if account.Properties != nil && account.Properties.PrimaryEndpoints != nil {
+		var endpoint *string
+		switch service {
+		case "blob":
+			endpoint = account.Properties.PrimaryEndpoints.Blob
+		case "table":
+			endpoint = account.Properties.PrimaryEndpoints.Table
+		case "queue":
+			endpoint = account.Properties.PrimaryEndpoints.Queue
+		case "file":
+			endpoint = account.Properties.PrimaryEndpoints.File
+		}
+		if endpoint != nil && *endpoint != "" {
+			return *endpoint
+		}
+	}
+	return GetCloudFromContext(ctx).StorageEndpoint(*account.Name, service)
+}
+
+// storageEnvironment is the autorest environment of the legacy storage data plane clients.
+func storageEnvironment(ctx context.Context) azure.Environment {
+	return azure.Environment{StorageEndpointSuffix: GetCloudFromContext(ctx).StorageEndpointSuffix}
+}
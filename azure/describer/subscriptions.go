@@ -2,14 +2,14 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/subscription/armsubscription"
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func Tenant(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsubscription.NewClientFactory(cred, nil)
+func Tenant(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsubscription.NewClientFactory(cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -53,12 +53,12 @@ func GetTenand(ctx context.Context, v *armsubscription.TenantIDDescription) *Res
 	return &resource
 }
 
-func Subscription(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsubscription.NewClientFactory(cred, nil)
+func Subscription(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsubscription.NewClientFactory(cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
-	resourceClientFactory, err := armresources.NewClientFactory(subscription, cred, nil)
+	resourceClientFactory, err := armresources.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
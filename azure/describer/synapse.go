@@ -2,7 +2,7 @@ package describer
 
 import (
 	"context"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/synapse/armsynapse"
 	"strings"
@@ -10,15 +10,15 @@ import (
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func SynapseWorkspace(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsynapse.NewClientFactory(subscription, cred, nil)
+func SynapseWorkspace(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsynapse.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 	synapseClient := clientFactory.NewWorkspaceManagedSQLServerVulnerabilityAssessmentsClient()
 	client := clientFactory.NewWorkspacesClient()
 
-	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, nil)
+	monitorClientFactory, err := armmonitor.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -97,8 +97,8 @@ func GetSynapseWorkspace(ctx context.Context, synapseClient *armsynapse.Workspac
 	return &resource, nil
 }
 
-func SynapseWorkspaceBigdataPools(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsynapse.NewClientFactory(subscription, cred, nil)
+func SynapseWorkspaceBigdataPools(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsynapse.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -115,6 +115,9 @@ func SynapseWorkspaceBigdataPools(ctx context.Context, cred *azidentity.ClientSe
 		for _, v := range page.Value {
 			resources, err := ListSynapseWorkspaceBigdataPools(ctx, bigDataPoolsClient, v)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *v.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			values = append(values, resources...)
@@ -157,8 +160,8 @@ func GetSynapseWorkspaceBigdataPools(ctx context.Context, resourceGroup string,
 	return &resource
 }
 
-func SynapseWorkspaceSqlpools(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	clientFactory, err := armsynapse.NewClientFactory(subscription, cred, nil)
+func SynapseWorkspaceSqlpools(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	clientFactory, err := armsynapse.NewClientFactory(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -175,6 +178,9 @@ func SynapseWorkspaceSqlpools(ctx context.Context, cred *azidentity.ClientSecret
 		for _, v := range page.Value {
 			resources, err := ListSynapseWorkspaceSqlpools(ctx, bpClient, v)
 			if err != nil {
+				if ReportScopeFailure(ctx, ScopeTypeResource, *v.ID, err) {
+					continue
+				}
 				return nil, err
 			}
 			for _, resource := range resources {
@@ -0,0 +1,213 @@
+package describer
+
+import (
+	"reflect"
+
+	"github.com/opengovern/og-azure-describer/azure/model"
+)
+
+// descriptionTagsFields maps the descriptions to their field holding the ARM model of the described resource,
+// whose Tags are the tags of the resource, "" if the description has the Tags itself. The descriptions missing
+// from it have no tags of their own, e.g. the child resources only embedding the model of their parent.
+var descriptionTagsFields = map[reflect.Type]string{
+	reflect.TypeOf(model.APIManagementDescription{}):                                 "APIManagement",
+	reflect.TypeOf(model.AnalysisServiceServerDescription{}):                         "Server",
+	reflect.TypeOf(model.AppConfigurationDescription{}):                              "ConfigurationStore",
+	reflect.TypeOf(model.AppManagedEnvironmentDescription{}):                         "HostingEnvironment",
+	reflect.TypeOf(model.AppServiceEnvironmentDescription{}):                         "AppServiceEnvironmentResource",
+	reflect.TypeOf(model.AppServiceFunctionAppDescription{}):                         "Site",
+	reflect.TypeOf(model.AppServicePlanDescription{}):                                "Plan",
+	reflect.TypeOf(model.AppServiceWebAppDescription{}):                              "Site",
+	reflect.TypeOf(model.AppServiceWebAppSlotDescription{}):                          "Site",
+	reflect.TypeOf(model.ApplicationGatewayDescription{}):                            "ApplicationGateway",
+	reflect.TypeOf(model.ApplicationInsightsComponentDescription{}):                  "Component",
+	reflect.TypeOf(model.AutomationAccountsDescription{}):                            "Automation",
+	reflect.TypeOf(model.AutoscaleSettingDescription{}):                              "AutoscaleSettingsResource",
+	reflect.TypeOf(model.BastionHostsDescription{}):                                  "BastianHost",
+	reflect.TypeOf(model.BatchAccountDescription{}):                                  "Account",
+	reflect.TypeOf(model.BotServiceBotDescription{}):                                 "Bot",
+	reflect.TypeOf(model.CDNEndpointDescription{}):                                   "Endpoint",
+	reflect.TypeOf(model.CDNProfileDescription{}):                                    "Profile",
+	reflect.TypeOf(model.CognitiveAccountDescription{}):                              "Account",
+	reflect.TypeOf(model.ComputeAvailabilitySetDescription{}):                        "AvailabilitySet",
+	reflect.TypeOf(model.ComputeCloudServiceDescription{}):                           "CloudService",
+	reflect.TypeOf(model.ComputeDiskAccessDescription{}):                             "DiskAccess",
+	reflect.TypeOf(model.ComputeDiskDescription{}):                                   "Disk",
+	reflect.TypeOf(model.ComputeDiskEncryptionSetDescription{}):                      "DiskEncryptionSet",
+	reflect.TypeOf(model.ComputeHostGroupDescription{}):                              "HostGroup",
+	reflect.TypeOf(model.ComputeHostGroupHostDescription{}):                          "Host",
+	reflect.TypeOf(model.ComputeImageDescription{}):                                  "Image",
+	reflect.TypeOf(model.ComputeImageGalleryDescription{}):                           "ImageGallery",
+	reflect.TypeOf(model.ComputeRestorePointCollectionDescription{}):                 "RestorePointCollection",
+	reflect.TypeOf(model.ComputeSSHPublicKeyDescription{}):                           "SSHPublicKey",
+	reflect.TypeOf(model.ComputeSnapshotsDescription{}):                              "Snapshot",
+	reflect.TypeOf(model.ComputeVirtualMachineDescription{}):                         "VirtualMachine",
+	reflect.TypeOf(model.ComputeVirtualMachineScaleSetDescription{}):                 "VirtualMachineScaleSet",
+	reflect.TypeOf(model.ComputeVirtualMachineScaleSetNetworkInterfaceDescription{}): "NetworkInterface",
+	reflect.TypeOf(model.ComputeVirtualMachineScaleSetVmDescription{}):               "ScaleSetVM",
+	reflect.TypeOf(model.ConnectionDescription{}):                                    "Connection",
+	reflect.TypeOf(model.ContainerAppDescription{}):                                  "Server",
+	reflect.TypeOf(model.ContainerInstanceContainerGroupDescription{}):               "ContainerGroup",
+	reflect.TypeOf(model.ContainerRegistryDescription{}):                             "Registry",
+	reflect.TypeOf(model.CosmosdbAccountDescription{}):                               "DatabaseAccountGetResults",
+	reflect.TypeOf(model.CosmosdbCassandraClusterDescription{}):                      "CassandraCluster",
+	reflect.TypeOf(model.CosmosdbMongoCollectionDescription{}):                       "MongoCollection",
+	reflect.TypeOf(model.CosmosdbMongoDatabaseDescription{}):                         "MongoDatabase",
+	reflect.TypeOf(model.CosmosdbSqlDatabaseDescription{}):                           "SqlDatabase",
+	reflect.TypeOf(model.DNSResolverDescription{}):                                   "DNSResolver",
+	reflect.TypeOf(model.DNSZoneDescription{}):                                       "Zone",
+	reflect.TypeOf(model.DNSZonesDescription{}):                                      "DNSZone",
+	reflect.TypeOf(model.DashboardGrafanaDescription{}):                              "Grafana",
+	reflect.TypeOf(model.DataFactoryDescription{}):                                   "Factory",
+	reflect.TypeOf(model.DataLakeAnalyticsAccountDescription{}):                      "DataLakeAnalyticsAccount",
+	reflect.TypeOf(model.DataLakeStoreDescription{}):                                 "DataLakeStoreAccount",
+	reflect.TypeOf(model.DataMigrationServiceDescription{}):                          "Service",
+	reflect.TypeOf(model.DataProtectionBackupVaultsDescription{}):                    "BackupVaults",
+	reflect.TypeOf(model.DataboxEdgeDeviceDescription{}):                             "Device",
+	reflect.TypeOf(model.DatabricksWorkspaceDescription{}):                           "Workspace",
+	reflect.TypeOf(model.DesktopVirtualizationHostPoolDescription{}):                 "HostPool",
+	reflect.TypeOf(model.DesktopVirtualizationWorkspaceDescription{}):                "Workspace",
+	reflect.TypeOf(model.DevTestLabLabDescription{}):                                 "Lab",
+	reflect.TypeOf(model.EventGridDomainDescription{}):                               "Domain",
+	reflect.TypeOf(model.EventGridTopicDescription{}):                                "Topic",
+	reflect.TypeOf(model.EventhubNamespaceDescription{}):                             "EHNamespace",
+	reflect.TypeOf(model.ExpressRouteCircuitDescription{}):                           "ExpressRouteCircuit",
+	reflect.TypeOf(model.FirewallPolicyDescription{}):                                "FirewallPolicy",
+	reflect.TypeOf(model.FrontdoorDescription{}):                                     "FrontDoor",
+	reflect.TypeOf(model.FrontdoorWebApplicationFirewallPolicyDescription{}):         "WebApplicationFirewallPolicy",
+	reflect.TypeOf(model.GenericResourceDescription{}):                               "GenericResource",
+	reflect.TypeOf(model.HdinsightClusterDescription{}):                              "Cluster",
+	reflect.TypeOf(model.HealthcareServiceDescription{}):                             "ServicesDescription",
+	reflect.TypeOf(model.HpcCacheDescription{}):                                      "Cache",
+	reflect.TypeOf(model.HybridComputeMachineDescription{}):                          "Machine",
+	reflect.TypeOf(model.HybridKubernetesConnectedClusterDescription{}):              "ConnectedCluster",
+	reflect.TypeOf(model.IOTHubDescription{}):                                        "IotHubDescription",
+	reflect.TypeOf(model.IOTHubDpsDescription{}):                                     "IotHubDps",
+	reflect.TypeOf(model.KeyVaultDescription{}):                                      "Resource",
+	reflect.TypeOf(model.KeyVaultKeyDescription{}):                                   "Key",
+	reflect.TypeOf(model.KeyVaultKeyVersionDescription{}):                            "Version",
+	reflect.TypeOf(model.KeyVaultManagedHardwareSecurityModuleDescription{}):         "ManagedHsm",
+	reflect.TypeOf(model.KeyVaultSecretDescription{}):                                "SecretItem",
+	reflect.TypeOf(model.KubernetesClusterDescription{}):                             "ManagedCluster",
+	reflect.TypeOf(model.KustoClusterDescription{}):                                  "Cluster",
+	reflect.TypeOf(model.LoadBalancerDescription{}):                                  "LoadBalancer",
+	reflect.TypeOf(model.LocalNetworkGatewayDescription{}):                           "LocalNetworkGateway",
+	reflect.TypeOf(model.LogAlertDescription{}):                                      "ActivityLogAlertResource",
+	reflect.TypeOf(model.LogProfileDescription{}):                                    "LogProfileResource",
+	reflect.TypeOf(model.LogicAppWorkflowDescription{}):                              "Workflow",
+	reflect.TypeOf(model.LogicIntegrationAccountsDescription{}):                      "Account",
+	reflect.TypeOf(model.MachineLearningWorkspaceDescription{}):                      "Workspace",
+	reflect.TypeOf(model.MaintenanceConfigurationDescription{}):                      "MaintenanceConfiguration",
+	reflect.TypeOf(model.MariadbServerDescription{}):                                 "Server",
+	reflect.TypeOf(model.MonitorLogProfileDescription{}):                             "LogProfile",
+	reflect.TypeOf(model.MssqlManagedInstanceDatabasesDescription{}):                 "Database",
+	reflect.TypeOf(model.MssqlManagedInstanceDescription{}):                          "ManagedInstance",
+	reflect.TypeOf(model.MysqlFlexibleserverDescription{}):                           "Server",
+	reflect.TypeOf(model.MysqlServerDescription{}):                                   "Server",
+	reflect.TypeOf(model.NatGatewayDescription{}):                                    "NatGateway",
+	reflect.TypeOf(model.NetAppAccountDescription{}):                                 "Account",
+	reflect.TypeOf(model.NetAppCapacityPoolDescription{}):                            "CapacityPool",
+	reflect.TypeOf(model.NetworkApplicationSecurityGroupsDescription{}):              "ApplicationSecurityGroup",
+	reflect.TypeOf(model.NetworkAzureFirewallDescription{}):                          "AzureFirewall",
+	reflect.TypeOf(model.NetworkDDoSProtectionPlanDescription{}):                     "DDoSProtectionPlan",
+	reflect.TypeOf(model.NetworkInterfaceDescription{}):                              "Interface",
+	reflect.TypeOf(model.NetworkSecurityGroupDescription{}):                          "SecurityGroup",
+	reflect.TypeOf(model.NetworkWatcherDescription{}):                                "Watcher",
+	reflect.TypeOf(model.NetworkWatcherFlowLogDescription{}):                         "FlowLog",
+	reflect.TypeOf(model.OperationalInsightsWorkspacesDescription{}):                 "Workspace",
+	reflect.TypeOf(model.PolicyAssignmentDescription{}):                              "Resource",
+	reflect.TypeOf(model.PostgresqlFlexibleServerDescription{}):                      "Server",
+	reflect.TypeOf(model.PostgresqlServerDescription{}):                              "Server",
+	reflect.TypeOf(model.PowerBIDedicatedCapacityDescription{}):                      "Capacity",
+	reflect.TypeOf(model.PrivateDNSZonesDescription{}):                               "PrivateZone",
+	reflect.TypeOf(model.PrivateEndpointDescription{}):                               "PrivateEndpoint",
+	reflect.TypeOf(model.PrivateLinkServiceDescription{}):                            "PrivateLinkService",
+	reflect.TypeOf(model.PublicIPAddressDescription{}):                               "PublicIPAddress",
+	reflect.TypeOf(model.PublicIPPrefixDescription{}):                                "PublicIPPrefix",
+	reflect.TypeOf(model.PurviewAccountDescription{}):                                "Account",
+	reflect.TypeOf(model.RecoveryServicesBackupItemDescription{}):                    "Item",
+	reflect.TypeOf(model.RecoveryServicesBackupJobDescription{}):                     "Job",
+	reflect.TypeOf(model.RecoveryServicesBackupPolicyDescription{}):                  "Policy",
+	reflect.TypeOf(model.RecoveryServicesVaultDescription{}):                         "Vault",
+	reflect.TypeOf(model.RedisCacheDescription{}):                                    "ResourceInfo",
+	reflect.TypeOf(model.RedisEnterpriseCacheDescription{}):                          "RedisEnterprise",
+	reflect.TypeOf(model.ResourceGroupDescription{}):                                 "Group",
+	reflect.TypeOf(model.RouteFilterDescription{}):                                   "RouteFilter",
+	reflect.TypeOf(model.RouteTablesDescription{}):                                   "RouteTable",
+	reflect.TypeOf(model.SearchServiceDescription{}):                                 "Service",
+	reflect.TypeOf(model.SecurityCenterAutomationDescription{}):                      "Automation",
+	reflect.TypeOf(model.ServiceFabricClusterDescription{}):                          "Cluster",
+	reflect.TypeOf(model.ServicebusNamespaceDescription{}):                           "SBNamespace",
+	reflect.TypeOf(model.SignalrServiceDescription{}):                                "ResourceInfo",
+	reflect.TypeOf(model.SpringCloudServiceDescription{}):                            "Site",
+	reflect.TypeOf(model.SqlDatabaseDescription{}):                                   "Database",
+	reflect.TypeOf(model.SqlInstancePoolDescription{}):                               "InstancePool",
+	reflect.TypeOf(model.SqlServerDescription{}):                                     "Server",
+	reflect.TypeOf(model.SqlServerElasticPoolDescription{}):                          "Pool",
+	reflect.TypeOf(model.SqlServerFlexibleServerDescription{}):                       "FlexibleServer",
+	reflect.TypeOf(model.SqlServerJobAgentDescription{}):                             "JobAgent",
+	reflect.TypeOf(model.SqlServerVirtualMachineDescription{}):                       "VirtualMachine",
+	reflect.TypeOf(model.SqlServerVirtualMachineGroupDescription{}):                  "Group",
+	reflect.TypeOf(model.SqlVirtualClustersDescription{}):                            "VirtualClusters",
+	reflect.TypeOf(model.StorageAccountDescription{}):                                "Account",
+	reflect.TypeOf(model.StorageSyncDescription{}):                                   "Service",
+	reflect.TypeOf(model.StreamAnalyticsClusterDescription{}):                        "StreamingJob",
+	reflect.TypeOf(model.StreamAnalyticsJobDescription{}):                            "StreamingJob",
+	reflect.TypeOf(model.SubscriptionDescription{}):                                  "",
+	reflect.TypeOf(model.SynapseWorkspaceBigdatapoolsDescription{}):                  "BigDataPool",
+	reflect.TypeOf(model.SynapseWorkspaceDescription{}):                              "Workspace",
+	reflect.TypeOf(model.SynapseWorkspaceSqlpoolsDescription{}):                      "SqlPool",
+	reflect.TypeOf(model.TimeSeriesInsightsEnvironmentsDescription{}):                "Environment",
+	reflect.TypeOf(model.TrafficManagerProfileDescription{}):                         "Profile",
+	reflect.TypeOf(model.VirtualHubsDescription{}):                                   "VirtualHub",
+	reflect.TypeOf(model.VirtualMachineImagesImageTemplatesDescription{}):            "ImageTemplate",
+	reflect.TypeOf(model.VirtualNetworkDescription{}):                                "VirtualNetwork",
+	reflect.TypeOf(model.VirtualNetworkGatewayDescription{}):                         "VirtualNetworkGateway",
+	reflect.TypeOf(model.VirtualWansDescription{}):                                   "VirtualWan",
+	reflect.TypeOf(model.VpnGatewayDescription{}):                                    "VpnGateway",
+	reflect.TypeOf(model.VpnSiteDescription{}):                                       "VpnSite",
+	reflect.TypeOf(model.WebServerFarmsDescription{}):                                "ServerFarm",
+}
+
+// tagsOfDescription returns the tags of the resource of an ARM description, nil if it has none.
+func tagsOfDescription(description interface{}) map[string]string {
+	v := reflect.ValueOf(description)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	field, ok := descriptionTagsFields[v.Type()]
+	if !ok {
+		return nil
+	}
+	if field != "" {
+		v = v.FieldByName(field)
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+	}
+
+	tags := map[string]string{}
+	iter := v.FieldByName("Tags").MapRange()
+	for iter.Next() {
+		value := iter.Value()
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				continue
+			}
+			value = value.Elem()
+		}
+		if value.Kind() == reflect.String {
+			tags[iter.Key().String()] = value.String()
+		}
+	}
+	return tags
+}
@@ -0,0 +1,260 @@
+package describer
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"golang.org/x/time/rate"
+)
+
+const (
+	remainingSubscriptionReadsHeader = "x-ms-ratelimit-remaining-subscription-reads"
+	retryAfterHeader                 = "Retry-After"
+)
+
+// ThrottlerConfig configures the token buckets of a Throttler.
+// ARM grants every subscription a bucket of 250 reads refilled at 25 reads per second,
+// see https://learn.microsoft.com/en-us/azure/azure-resource-manager/management/request-limits-and-throttling
+type ThrottlerConfig struct {
+	// SubscriptionRate and SubscriptionBurst bound the requests sent to a single subscription.
+	SubscriptionRate  float64
+	SubscriptionBurst int
+	// ProviderRate and ProviderBurst bound the requests sent to a single resource provider of a subscription.
+	ProviderRate  float64
+	ProviderBurst int
+	// LowRemainingReads is the x-ms-ratelimit-remaining-subscription-reads value below which the subscription
+	// is paused for LowRemainingReadsPause.
+	LowRemainingReads      int
+	LowRemainingReadsPause time.Duration
+}
+
+var DefaultThrottlerConfig = ThrottlerConfig{
+	SubscriptionRate:       20,
+	SubscriptionBurst:      200,
+	ProviderRate:           10,
+	ProviderBurst:          100,
+	LowRemainingReads:      50,
+	LowRemainingReadsPause: 10 * time.Second,
+}
+
+// ThrottlingStats are the counters of a Throttler.
+type ThrottlingStats struct {
+	Requests           int64
+	ThrottledResponses int64
+	LowQuotaResponses  int64
+	Waits              int64
+	WaitDuration       time.Duration
+}
+
+// ThrottlingCounters count the requests of a Throttler, for the whole process or for the describe calls of
+// a context, see WithThrottlingCounters.
+type ThrottlingCounters struct {
+	requests           atomic.Int64
+	throttledResponses atomic.Int64
+	lowQuotaResponses  atomic.Int64
+	waits              atomic.Int64
+	waitDuration       atomic.Int64
+}
+
+func (c *ThrottlingCounters) Stats() ThrottlingStats {
+	return ThrottlingStats{
+		Requests:           c.requests.Load(),
+		ThrottledResponses: c.throttledResponses.Load(),
+		LowQuotaResponses:  c.lowQuotaResponses.Load(),
+		Waits:              c.waits.Load(),
+		WaitDuration:       time.Duration(c.waitDuration.Load()),
+	}
+}
+
+// Throttler is an azcore policy limiting the requests sent to ARM with token buckets keyed by subscription
+// and by resource provider. It pauses the requests to a subscription when ARM asks to with Retry-After or
+// when its remaining reads quota runs low.
+type Throttler struct {
+	config ThrottlerConfig
+
+	mu          sync.Mutex
+	limiters    map[string]*rate.Limiter
+	pausedUntil map[string]time.Time
+
+	counters ThrottlingCounters
+}
+
+func NewThrottler(config ThrottlerConfig) *Throttler {
+	return &Throttler{
+		config:      config,
+		limiters:    map[string]*rate.Limiter{},
+		pausedUntil: map[string]time.Time{},
+	}
+}
+
+// DefaultThrottler is shared by all the describe jobs of the process, so the concurrent jobs
+// on the same subscription share its quota.
+var DefaultThrottler = NewThrottler(DefaultThrottlerConfig)
+
+// Stats returns the counters of all the requests sent through the throttler.
+func (t *Throttler) Stats() ThrottlingStats {
+	return t.counters.Stats()
+}
+
+// count updates the counters of the throttler and the ones of the describe call of ctx.
+func (t *Throttler) count(ctx context.Context, f func(c *ThrottlingCounters)) {
+	f(&t.counters)
+	if c := getThrottlingCountersFromContext(ctx); c != nil {
+		f(c)
+	}
+}
+
+func (t *Throttler) Do(req *policy.Request) (*http.Response, error) {
+	ctx := req.Raw().Context()
+	subscription, provider := subscriptionAndProviderOf(req.Raw().URL.Path)
+	keys := t.keysOf(subscription, provider)
+
+	if err := t.wait(ctx, keys); err != nil {
+		return nil, err
+	}
+
+	t.count(ctx, func(c *ThrottlingCounters) { c.requests.Add(1) })
+	resp, err := req.Next()
+	if err != nil || resp == nil {
+		return resp, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		t.count(ctx, func(c *ThrottlingCounters) { c.throttledResponses.Add(1) })
+	}
+	if after, ok := retryAfter(resp.Header); ok && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		t.pause(keys, after)
+	}
+	if remaining, err := strconv.Atoi(resp.Header.Get(remainingSubscriptionReadsHeader)); err == nil && subscription != "" && remaining < t.config.LowRemainingReads {
+		t.count(ctx, func(c *ThrottlingCounters) { c.lowQuotaResponses.Add(1) })
+		t.pause(keys[:1], t.config.LowRemainingReadsPause)
+	}
+	// Resource Graph has its own per user quota
+	if remaining, after, err := quota(resp.Header); err == nil && remaining == 0 {
+		t.count(ctx, func(c *ThrottlingCounters) { c.lowQuotaResponses.Add(1) })
+		t.pause(keys, after)
+	}
+
+	return resp, nil
+}
+
+func (t *Throttler) keysOf(subscription, provider string) []string {
+	if subscription == "" {
+		subscription = "tenant"
+	}
+	keys := []string{"subscription/" + subscription}
+	if provider != "" {
+		keys = append(keys, "subscription/"+subscription+"/provider/"+provider)
+	}
+	return keys
+}
+
+func (t *Throttler) limiter(key string) *rate.Limiter {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	l, ok := t.limiters[key]
+	if !ok {
+		if strings.Contains(key, "/provider/") {
+			l = rate.NewLimiter(rate.Limit(t.config.ProviderRate), t.config.ProviderBurst)
+		} else {
+			l = rate.NewLimiter(rate.Limit(t.config.SubscriptionRate), t.config.SubscriptionBurst)
+		}
+		t.limiters[key] = l
+	}
+	return l
+}
+
+func (t *Throttler) pause(keys []string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	until := time.Now().Add(d)
+	for _, key := range keys {
+		if until.After(t.pausedUntil[key]) {
+			t.pausedUntil[key] = until
+		}
+	}
+}
+
+func (t *Throttler) pausedFor(keys []string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var d time.Duration
+	for _, key := range keys {
+		if until := time.Until(t.pausedUntil[key]); until > d {
+			d = until
+		}
+	}
+	return d
+}
+
+func (t *Throttler) wait(ctx context.Context, keys []string) error {
+	start := time.Now()
+	defer func() {
+		if waited := time.Since(start); waited > time.Millisecond {
+			t.count(ctx, func(c *ThrottlingCounters) {
+				c.waits.Add(1)
+				c.waitDuration.Add(int64(waited))
+			})
+		}
+	}()
+
+	if d := t.pausedFor(keys); d > 0 {
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	for _, key := range keys {
+		if err := t.limiter(key).Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subscriptionAndProviderOf extracts the subscription ID and the resource provider namespace of an ARM request path,
+// e.g. /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Compute/virtualMachines.
+func subscriptionAndProviderOf(path string) (string, string) {
+	var subscription, provider string
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		switch strings.ToLower(parts[i]) {
+		case "subscriptions":
+			if subscription == "" {
+				subscription = strings.ToLower(parts[i+1])
+			}
+		case "providers":
+			if provider == "" {
+				provider = strings.ToLower(parts[i+1])
+			}
+		}
+	}
+	return subscription, provider
+}
+
+func retryAfter(header http.Header) (time.Duration, bool) {
+	v := header.Get(retryAfterHeader)
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t), true
+	}
+	return 0, false
+}
@@ -0,0 +1,46 @@
+package describer
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+type throttledTransport struct{}
+
+func (throttledTransport) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+}
+
+func TestThrottlingCounters(t *testing.T) {
+	throttler := NewThrottler(DefaultThrottlerConfig)
+	pl := runtime.NewPipeline("test", "v0", runtime.PipelineOptions{PerRetry: []policy.Policy{throttler}}, &policy.ClientOptions{
+		Transport: throttledTransport{},
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	})
+
+	jobCounters := &ThrottlingCounters{}
+	send := func(ctx context.Context) {
+		req, err := runtime.NewRequest(ctx, http.MethodGet, "https://management.azure.com/subscriptions/s/providers/Microsoft.Compute/virtualMachines")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := pl.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	send(WithThrottlingCounters(context.Background(), jobCounters))
+	send(context.Background())
+
+	if stats := throttler.Stats(); stats.Requests != 2 || stats.ThrottledResponses != 2 {
+		t.Errorf("got throttler stats %+v, want 2 requests", stats)
+	}
+	if stats := jobCounters.Stats(); stats.Requests != 1 || stats.ThrottledResponses != 1 {
+		t.Errorf("got job stats %+v, want 1 request", stats)
+	}
+}
@@ -5,14 +5,15 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	appservice "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
 
 	"github.com/opengovern/og-azure-describer/azure/model"
 )
 
-func AppServiceEnvironment(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := appservice.NewEnvironmentsClient(subscription, cred, nil)
+func AppServiceEnvironment(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := appservice.NewEnvironmentsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -56,13 +57,13 @@ func GetAppServiceEnvironment(ctx context.Context, v *appservice.EnvironmentReso
 	return &resource
 }
 
-func AppServiceFunctionApp(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := appservice.NewWebAppsClient(subscription, cred, nil)
+func AppServiceFunctionApp(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := appservice.NewWebAppsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 
-	webClient, err := appservice.NewWebAppsClient(subscription, cred, nil)
+	webClient, err := appservice.NewWebAppsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -91,6 +92,19 @@ func AppServiceFunctionApp(ctx context.Context, cred *azidentity.ClientSecretCre
 	return values, err
 }
 
+func AppServiceFunctionAppByID(ctx context.Context, cred azcore.TokenCredential, id *arm.ResourceID) (*Resource, error) {
+	webClient, err := appservice.NewWebAppsClient(id.SubscriptionID, cred, ClientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	site, err := webClient.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return GetAppServiceFunctionApp(ctx, webClient, &site.Site)
+}
+
 func GetAppServiceFunctionApp(ctx context.Context, webClient *appservice.WebAppsClient, v *appservice.Site) (*Resource, error) {
 	resourceGroup := strings.Split(*v.ID, "/")[4]
 
@@ -118,41 +132,70 @@ func GetAppServiceFunctionApp(ctx context.Context, webClient *appservice.WebApps
 	return &resource, nil
 }
 
-func AppServiceWebApp(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := appservice.NewWebAppsClient(subscription, cred, nil)
+func AppServiceWebApp(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := appservice.NewWebAppsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
-	webClient, err := appservice.NewWebAppsClient(subscription, cred, nil)
+	webClient, err := appservice.NewWebAppsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 
 	var values []Resource
-	pager := client.NewListPager(nil)
-	for pager.More() {
-		page, err := pager.NextPage(ctx)
-		if err != nil {
-			return nil, err
-		}
-		for _, v := range page.Value {
+	describe := func(sites []*appservice.Site) error {
+		for _, v := range sites {
+			if !InScope(ctx, *v.ID, v.Tags) {
+				continue
+			}
 			resource, err := GetAppServiceWebApp(ctx, webClient, v)
 			if err != nil {
-				return nil, err
+				return err
 			}
 			if resource == nil {
 				continue
 			}
 			if stream != nil {
 				if err := (*stream)(*resource); err != nil {
-					return nil, err
+					return err
 				}
 			} else {
 				values = append(values, *resource)
 			}
 		}
+		return nil
 	}
-	return values, err
+
+	if resourceGroups := GetResourceGroupsFromContext(ctx); len(resourceGroups) > 0 {
+		for _, resourceGroup := range resourceGroups {
+			pager := client.NewListByResourceGroupPager(resourceGroup, nil)
+			for pager.More() {
+				page, err := pager.NextPage(ctx)
+				if err != nil {
+					if isResourceGroupNotFound(err) || ReportScopeFailure(ctx, ScopeTypeResourceGroup, resourceGroupID(subscription, resourceGroup), err) {
+						break
+					}
+					return nil, err
+				}
+				if err := describe(page.Value); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return values, nil
+	}
+
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := describe(page.Value); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
 }
 
 func GetAppServiceWebApp(ctx context.Context, webClient *appservice.WebAppsClient, v *appservice.Site) (*Resource, error) {
@@ -223,8 +266,8 @@ func GetAppServiceWebApp(ctx context.Context, webClient *appservice.WebAppsClien
 	return &resource, nil
 }
 
-func AppServiceWebAppSlot(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := appservice.NewWebAppsClient(subscription, cred, nil)
+func AppServiceWebAppSlot(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := appservice.NewWebAppsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -290,8 +333,8 @@ func GetAppServiceWebAppSlot(ctx context.Context, app *appservice.Site, v *appse
 	return &resource
 }
 
-func AppServicePlan(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := appservice.NewPlansClient(subscription, cred, nil)
+func AppServicePlan(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := appservice.NewPlansClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -355,8 +398,8 @@ func GetAppServicePlan(ctx context.Context, client *appservice.PlansClient, v *a
 	return &resource, nil
 }
 
-func AppContainerApps(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := appservice.NewContainerAppsClient(subscription, cred, nil)
+func AppContainerApps(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := appservice.NewContainerAppsClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -400,8 +443,8 @@ func GetAppContainerApps(ctx context.Context, server *appservice.ContainerApp) *
 	return &resource
 }
 
-func WebServerFarms(ctx context.Context, cred *azidentity.ClientSecretCredential, subscription string, stream *StreamSender) ([]Resource, error) {
-	client, err := appservice.NewPlansClient(subscription, cred, nil)
+func WebServerFarms(ctx context.Context, cred azcore.TokenCredential, subscription string, stream *StreamSender) ([]Resource, error) {
+	client, err := appservice.NewPlansClient(subscription, cred, ClientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
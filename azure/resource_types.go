@@ -1362,7 +1362,7 @@ var resourceTypes = map[string]ResourceType{
 		},
 		ServiceName:          "Sql",
 		ListDescriber:        DescribeBySubscription(describer.SqlServer),
-		GetDescriber:         nil,
+		GetDescriber:         DescribeByResourceID(describer.SqlServerByID),
 		TerraformName:        []string{"azurerm_mssql_server"},
 		TerraformServiceName: "mssql",
 		FastDiscovery:        true,
@@ -1903,7 +1903,7 @@ var resourceTypes = map[string]ResourceType{
 		},
 		ServiceName:          "Web",
 		ListDescriber:        DescribeBySubscription(describer.AppServiceFunctionApp),
-		GetDescriber:         nil,
+		GetDescriber:         DescribeByResourceID(describer.AppServiceFunctionAppByID),
 		TerraformName:        []string{"azurerm_app_service", "azurerm_function_app"},
 		TerraformServiceName: "web",
 		FastDiscovery:        true,
@@ -2249,7 +2249,7 @@ var resourceTypes = map[string]ResourceType{
 		},
 		ServiceName:          "Network",
 		ListDescriber:        DescribeBySubscription(describer.NetworkSecurityGroup),
-		GetDescriber:         nil,
+		GetDescriber:         DescribeByResourceID(describer.NetworkSecurityGroupByID),
 		TerraformName:        []string{"azurerm_network_security_group"},
 		TerraformServiceName: "network",
 		FastDiscovery:        true,
@@ -3048,7 +3048,7 @@ var resourceTypes = map[string]ResourceType{
 		},
 		ServiceName:          "Compute",
 		ListDescriber:        DescribeBySubscription(describer.ComputeVirtualMachine),
-		GetDescriber:         nil,
+		GetDescriber:         DescribeByResourceID(describer.ComputeVirtualMachineByID),
 		TerraformName:        []string{"azurerm_linux_virtual_machine", "azurerm_windows_virtual_machine"},
 		TerraformServiceName: "compute",
 		FastDiscovery:        true,
@@ -3099,7 +3099,7 @@ var resourceTypes = map[string]ResourceType{
 		},
 		ServiceName:          "KeyVault",
 		ListDescriber:        DescribeBySubscription(describer.KeyVault),
-		GetDescriber:         nil,
+		GetDescriber:         DescribeByResourceID(describer.KeyVaultByID),
 		TerraformName:        []string{"azurerm_key_vault"},
 		TerraformServiceName: "keyvault",
 		FastDiscovery:        false,
@@ -3167,7 +3167,7 @@ var resourceTypes = map[string]ResourceType{
 		},
 		ServiceName:          "Storage",
 		ListDescriber:        DescribeBySubscription(describer.StorageAccount),
-		GetDescriber:         nil,
+		GetDescriber:         DescribeByResourceID(describer.StorageAccountByID),
 		TerraformName:        []string{"azurerm_storage_account"},
 		TerraformServiceName: "storage",
 		FastDiscovery:        true,
@@ -4,22 +4,20 @@ package azure
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go.uber.org/zap"
-	"os"
 	"sort"
 	"strings"
+	"sync"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 
+	"github.com/opengovern/og-util/pkg/concurrency"
 	"github.com/opengovern/og-util/pkg/describe/enums"
 	"github.com/opengovern/og-util/pkg/source"
 
-	"github.com/Azure/azure-sdk-for-go/profiles/latest/resourcegraph/mgmt/resourcegraph"
-	"github.com/Azure/go-autorest/autorest"
-	"github.com/Azure/go-autorest/autorest/azure/auth"
-	hamiltonAuthAutoRest "github.com/manicminer/hamilton-autorest/auth"
-	hamiltonAuth "github.com/manicminer/hamilton/auth"
 	"github.com/opengovern/og-azure-describer/azure/describer"
 )
 
@@ -28,19 +26,32 @@ const AzureAuthLocation = "AZURE_AUTH_LOCATION"
 type AuthType string
 
 const (
-	AuthEnv  AuthType = "ENV"
-	AuthFile AuthType = "FILE"
-	AuthCLI  AuthType = "CLI"
+	AuthEnv              AuthType = "ENV"
+	AuthFile             AuthType = "FILE"
+	AuthCLI              AuthType = "CLI"
+	AuthManagedIdentity  AuthType = "MANAGED_IDENTITY"
+	AuthWorkloadIdentity AuthType = "WORKLOAD_IDENTITY"
 )
 
 type ResourceDescriber interface {
-	DescribeResources(context.Context, *azidentity.ClientSecretCredential, hamiltonAuth.Authorizer, []string, string, enums.DescribeTriggerType, *describer.StreamSender) ([]describer.Resource, error)
+	DescribeResources(context.Context, azcore.TokenCredential, describer.DescribeOptions, *describer.StreamSender) ([]describer.Resource, error)
 }
 
-type ResourceDescribeFunc func(context.Context, *azidentity.ClientSecretCredential, hamiltonAuth.Authorizer, []string, string, enums.DescribeTriggerType, *describer.StreamSender) ([]describer.Resource, error)
+type ResourceDescribeFunc func(context.Context, azcore.TokenCredential, describer.DescribeOptions, *describer.StreamSender) ([]describer.Resource, error)
 
-func (fn ResourceDescribeFunc) DescribeResources(c context.Context, a *azidentity.ClientSecretCredential, ah hamiltonAuth.Authorizer, s []string, t string, triggerType enums.DescribeTriggerType, stream *describer.StreamSender) ([]describer.Resource, error) {
-	return fn(c, a, ah, s, t, triggerType, stream)
+func (fn ResourceDescribeFunc) DescribeResources(c context.Context, a azcore.TokenCredential, opts describer.DescribeOptions, stream *describer.StreamSender) ([]describer.Resource, error) {
+	return fn(c, a, opts, stream)
+}
+
+// SingleResourceDescriber describes a single resource given its ARM ID.
+type SingleResourceDescriber interface {
+	DescribeResource(context.Context, azcore.TokenCredential, describer.DescribeOptions, string) (*describer.Resource, error)
+}
+
+type SingleResourceDescribeFunc func(context.Context, azcore.TokenCredential, describer.DescribeOptions, string) (*describer.Resource, error)
+
+func (fn SingleResourceDescribeFunc) DescribeResource(c context.Context, a azcore.TokenCredential, opts describer.DescribeOptions, resourceID string) (*describer.Resource, error) {
+	return fn(c, a, opts, resourceID)
 }
 
 type ResourceType struct {
@@ -53,7 +64,7 @@ type ResourceType struct {
 	Tags map[string][]string
 
 	ListDescriber ResourceDescriber
-	GetDescriber  ResourceDescriber // TODO: Change the type?
+	GetDescriber  SingleResourceDescriber
 
 	TerraformName        []string
 	TerraformServiceName string
@@ -179,53 +190,44 @@ func GetResources(
 	azureAuthLoc string,
 	stream *describer.StreamSender,
 ) (*Resources, error) {
-	// Create and authorize a ResourceGraph client
-	var authorizer autorest.Authorizer
-	var err error
-	switch v := AuthType(strings.ToUpper(azureAuth)); v {
-	case AuthEnv:
-		authorizer, err = NewAuthorizerFromConfig(cfg)
-	case AuthFile:
-		setEnvIfNotEmpty(AzureAuthLocation, azureAuthLoc)
-		authorizer, err = auth.NewAuthorizerFromFile(resourcegraph.DefaultBaseURI)
-	case AuthCLI:
-		authorizer, err = auth.NewAuthorizerFromCLI()
-	default:
-		err = fmt.Errorf("invalid auth type: %s", v)
-	}
-
+	authType := AuthType(strings.ToUpper(azureAuth))
+	cloud, err := CloudFromConfig(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	hamiltonAuthorizer, err := hamiltonAuthAutoRest.NewAuthorizerWrapper(authorizer)
+	cred, err := NewTokenCredential(cfg, authType, azureAuthLoc)
 	if err != nil {
 		return nil, err
 	}
 
-	env, err := auth.GetSettingsFromEnvironment()
-	if err != nil {
+	// the other options, e.g. the time window or the resource group filter, are set by the caller with describer.WithDescribeOptions
+	opts := describer.GetDescribeOptionsFromContext(ctx)
+	opts.TenantID = cfg.TenantID
+	opts.Subscriptions = subscriptions
+	opts.TriggerType = triggerType
+	opts.Cloud = cloud
+	opts.Logger = logger
+
+	// the same resource can be listed more than once, e.g. by overlapping pages, it is only returned the first time
+	dedup := describer.GetDeduplicatorFromContext(ctx)
+	dropped := dedup.Dropped()
+
+	// With ContinueOnFailure the resources of the healthy subscriptions are still returned along with the error
+	resources, err := describe(ctx, cred, resourceType, opts, dedup.Stream(stream))
+	var subscriptionErrs describer.SubscriptionErrors
+	if err != nil && !errors.As(err, &subscriptionErrs) {
 		return nil, err
 	}
-
-	cred, err := azidentity.NewClientSecretCredential(cfg.TenantID, cfg.ClientID, cfg.ClientSecret, nil)
-	if err != nil {
-		return nil, err
+	if stream == nil {
+		resources = dedup.Filter(resources)
 	}
-
-	resources, err := describe(ctx, logger, cred, hamiltonAuthorizer, resourceType, subscriptions, cfg.TenantID, triggerType, stream)
-	if err != nil {
-		return nil, err
+	if dropped = dedup.Dropped() - dropped; dropped > 0 {
+		logger.Warn("dropped duplicate resources", zap.String("resourceType", resourceType), zap.Int64("duplicates", dropped))
 	}
 
-	for i, resource := range resources {
-		resources[i].Type = resourceType
-		if parts := strings.Split(resources[i].ID, "/"); len(parts) > 4 {
-			resources[i].ResourceGroup = strings.Split(resources[i].ID, "/")[4]
-		}
-		resources[i].Description = describer.JSONAllFieldsMarshaller{
-			Value: resource.Description,
-		}
+	for i := range resources {
+		finalizeResource(resourceType, &resources[i])
 	}
 
 	output := &Resources{
@@ -233,23 +235,125 @@ func GetResources(
 		Metadata: ResourceDescriptionMetadata{
 			ResourceType:     resourceType,
 			SubscriptionIds:  subscriptions,
-			CloudEnvironment: env.Environment.Name,
+			CloudEnvironment: cloud.Name,
 		},
 	}
 
 	return output, err
 }
 
-func setEnvIfNotEmpty(env, s string) {
-	if s != "" {
-		err := os.Setenv(env, s)
+// StreamResources describes resourceType like GetResources but hands the resources to fn as they are described
+// instead of returning them, so that the memory used does not grow with the number of resources. The calls to fn
+// are serialized and the describers wait for it to return, a slow fn slows the describe down rather than
+// buffering the resources. An error of fn stops the describe and is returned, describer.ErrMaxItemsReached
+// stops it without an error.
+func StreamResources(
+	ctx context.Context,
+	logger *zap.Logger,
+	resourceType string,
+	triggerType enums.DescribeTriggerType,
+	subscriptions []string,
+	cfg AuthConfig,
+	azureAuth string,
+	azureAuthLoc string,
+	fn func(describer.Resource) error,
+) error {
+	var mu sync.Mutex
+	var done bool
+	stream := describer.StreamSender(func(resource describer.Resource) error {
+		mu.Lock()
+		defer mu.Unlock()
+		// the subscriptions described in parallel stop at their next resource
+		if done {
+			return describer.ErrMaxItemsReached
+		}
+		finalizeResource(resourceType, &resource)
+		err := fn(resource)
+		done = errors.Is(err, describer.ErrMaxItemsReached)
+		return err
+	})
+
+	_, err := GetResources(ctx, logger, resourceType, triggerType, subscriptions, cfg, azureAuth, azureAuthLoc, &stream)
+	if errors.Is(err, describer.ErrMaxItemsReached) {
+		return nil
+	}
+	return err
+}
+
+// GetResource describes the single resource resourceID of resourceType, e.g. to refresh it after a change event.
+// It fails if the resource type has no GetDescriber.
+func GetResource(
+	ctx context.Context,
+	logger *zap.Logger,
+	resourceType string,
+	resourceID string,
+	cfg AuthConfig,
+	azureAuth string,
+	azureAuthLoc string,
+) (*describer.Resource, error) {
+	resourceTypeObject, err := GetResourceType(resourceType)
+	if err != nil {
+		return nil, err
+	}
+	if resourceTypeObject.GetDescriber == nil {
+		return nil, fmt.Errorf("single resource describe is not supported for %s", resourceTypeObject.ResourceName)
+	}
+	if id, err := arm.ParseResourceID(resourceID); err == nil && !strings.EqualFold(id.ResourceType.String(), resourceTypeObject.ResourceName) {
+		return nil, fmt.Errorf("resource %s is not a %s", resourceID, resourceTypeObject.ResourceName)
+	}
+
+	cloud, err := CloudFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cred, err := NewTokenCredential(cfg, AuthType(strings.ToUpper(azureAuth)), azureAuthLoc)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := describer.GetDescribeOptionsFromContext(ctx)
+	opts.TenantID = cfg.TenantID
+	opts.Cloud = cloud
+	opts.Logger = logger
+
+	resource, err := resourceTypeObject.GetDescriber.DescribeResource(ctx, cred, opts, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	finalizeResource(resourceTypeObject.ResourceName, resource)
+	return resource, nil
+}
+
+// DescribeByResourceID adapts a describer of a single resource to a SingleResourceDescriber.
+// The describer receives the parsed ARM ID, which must be of the resource type of the describer.
+func DescribeByResourceID(describe func(context.Context, azcore.TokenCredential, *arm.ResourceID) (*describer.Resource, error)) SingleResourceDescriber {
+	return SingleResourceDescribeFunc(func(ctx context.Context, cred azcore.TokenCredential, opts describer.DescribeOptions, resourceID string) (*describer.Resource, error) {
+		ctx = describer.WithDescribeOptions(ctx, opts)
+		id, err := arm.ParseResourceID(resourceID)
 		if err != nil {
-			panic(err)
+			return nil, fmt.Errorf("invalid resource id %s: %w", resourceID, err)
 		}
+
+		resource, err := describe(ctx, cred, id)
+		if err != nil {
+			return nil, err
+		}
+		resource.SubscriptionID = id.SubscriptionID
+		return resource, nil
+	})
+}
+
+func finalizeResource(resourceType string, resource *describer.Resource) {
+	resource.Type = resourceType
+	if parts := strings.Split(resource.ID, "/"); len(parts) > 4 {
+		resource.ResourceGroup = parts[4]
+	}
+	resource.Description = describer.JSONAllFieldsMarshaller{
+		Value: resource.Description,
 	}
 }
 
-func describe(ctx context.Context, logger *zap.Logger, cred *azidentity.ClientSecretCredential, hamiltonAuth hamiltonAuth.Authorizer, resourceType string, subscriptions []string, tenantId string, triggerType enums.DescribeTriggerType, stream *describer.StreamSender) ([]describer.Resource, error) {
+func describe(ctx context.Context, cred azcore.TokenCredential, resourceType string, opts describer.DescribeOptions, stream *describer.StreamSender) ([]describer.Resource, error) {
 	resourceTypeObject, ok := resourceTypes[resourceType]
 	if !ok {
 		return nil, fmt.Errorf("unsupported resource type: %s", resourceType)
@@ -259,41 +363,119 @@ func describe(ctx context.Context, logger *zap.Logger, cred *azidentity.ClientSe
 	if listDescriber == nil {
 		listDescriber = describer.GenericResourceGraph{Table: "Resources", Type: resourceType}
 	}
-	ctx = describer.WithLogger(ctx, logger)
 
-	return listDescriber.DescribeResources(ctx, cred, hamiltonAuth, subscriptions, tenantId, triggerType, stream)
+	return listDescriber.DescribeResources(ctx, cred, opts, stream)
 }
 
-func DescribeBySubscription(describe func(context.Context, *azidentity.ClientSecretCredential, string, *describer.StreamSender) ([]describer.Resource, error)) ResourceDescriber {
-	return ResourceDescribeFunc(func(ctx context.Context, client *azidentity.ClientSecretCredential, hamiltonAuth hamiltonAuth.Authorizer, subscriptions []string, tenantId string, triggerType enums.DescribeTriggerType, stream *describer.StreamSender) ([]describer.Resource, error) {
-		ctx = describer.WithTriggerType(ctx, triggerType)
-		values := []describer.Resource{}
-		for _, subscription := range subscriptions {
-			result, err := describe(ctx, client, subscription, stream)
-			if err != nil {
-				return nil, err
-			}
+// DescribeBySubscription runs describe for every subscription using a bounded worker pool.
+// The pool size and the failure behaviour are taken from the FanOut of the options.
+// By default the first failing subscription cancels the rest, with ContinueOnFailure the resources of the
+// successful subscriptions are returned alongside a describer.SubscriptionErrors.
+func DescribeBySubscription(describe func(context.Context, azcore.TokenCredential, string, *describer.StreamSender) ([]describer.Resource, error)) ResourceDescriber {
+	return ResourceDescribeFunc(func(ctx context.Context, client azcore.TokenCredential, opts describer.DescribeOptions, stream *describer.StreamSender) ([]describer.Resource, error) {
+		ctx = describer.WithDescribeOptions(ctx, opts)
+		fanOut := describer.GetSubscriptionFanOutFromContext(ctx)
+		subscriptions := opts.Subscriptions
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var abortErr error
+		var abortOnce sync.Once
+
+		limiter := describer.NewResultLimiter(opts)
+		stream = limiter.Stream(synchronizedStream(stream))
+		results := make([][]describer.Resource, len(subscriptions))
+		errs := make([]error, len(subscriptions))
+
+		wp := concurrency.NewWorkPool(fanOut.Concurrency)
+		for i, subscription := range subscriptions {
+			i, subscription := i, subscription
+			wp.AddJob(func() (interface{}, error) {
+				if stream != nil && limiter.Done() {
+					return nil, nil
+				}
+				result, err := describeSubscription(ctx, describe, client, subscription, stream)
+				if errors.Is(err, describer.ErrMaxItemsReached) {
+					err = nil
+				}
+				if err != nil {
+					errs[i] = err
+					if !fanOut.ContinueOnFailure {
+						abortOnce.Do(func() {
+							abortErr = describer.SubscriptionError{SubscriptionID: subscription, Err: err}
+							cancel()
+						})
+					}
+					return nil, err
+				}
+
+				for j := range result {
+					result[j].SubscriptionID = subscription
+				}
+				results[i] = result
+				return nil, nil
+			})
+		}
+		wp.Run()
+
+		if abortErr != nil {
+			return nil, abortErr
+		}
 
-			for _, resource := range result {
-				resource.SubscriptionID = subscription
+		values := []describer.Resource{}
+		var subscriptionErrs describer.SubscriptionErrors
+		for i, subscription := range subscriptions {
+			if errs[i] != nil {
+				subscriptionErrs = append(subscriptionErrs, describer.SubscriptionError{SubscriptionID: subscription, Err: errs[i]})
+				continue
 			}
-			values = append(values, result...)
+			values = append(values, results[i]...)
 		}
+		values = limiter.Filter(values)
 
+		if len(subscriptionErrs) > 0 {
+			return values, subscriptionErrs
+		}
 		return values, nil
 	})
 }
 
-func DescribeADByTenantID(describe func(context.Context, *azidentity.ClientSecretCredential, string, *describer.StreamSender) ([]describer.Resource, error)) ResourceDescriber {
-	return ResourceDescribeFunc(func(ctx context.Context, cred *azidentity.ClientSecretCredential, hamiltonAuth hamiltonAuth.Authorizer, subscription []string, tenantId string, triggerType enums.DescribeTriggerType, stream *describer.StreamSender) ([]describer.Resource, error) {
-		ctx = describer.WithTriggerType(ctx, triggerType)
+func describeSubscription(ctx context.Context, describe func(context.Context, azcore.TokenCredential, string, *describer.StreamSender) ([]describer.Resource, error), client azcore.TokenCredential, subscription string, stream *describer.StreamSender) (result []describer.Resource, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("paniced with %v", r)
+		}
+	}()
+	return describe(ctx, client, subscription, stream)
+}
+
+// synchronizedStream serializes the calls to stream, so it can be shared between subscriptions described in parallel.
+func synchronizedStream(stream *describer.StreamSender) *describer.StreamSender {
+	if stream == nil {
+		return nil
+	}
+
+	var mu sync.Mutex
+	f := describer.StreamSender(func(resource describer.Resource) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return (*stream)(resource)
+	})
+	return &f
+}
+
+func DescribeADByTenantID(describe func(context.Context, azcore.TokenCredential, string, *describer.StreamSender) ([]describer.Resource, error)) ResourceDescriber {
+	return ResourceDescribeFunc(func(ctx context.Context, cred azcore.TokenCredential, opts describer.DescribeOptions, stream *describer.StreamSender) ([]describer.Resource, error) {
+		ctx = describer.WithDescribeOptions(ctx, opts)
+		limiter := describer.NewResultLimiter(opts)
 		var values []describer.Resource
-		result, err := describe(ctx, cred, tenantId, stream)
-		if err != nil {
+		result, err := describe(ctx, cred, opts.TenantID, limiter.Stream(stream))
+		if err != nil && !errors.Is(err, describer.ErrMaxItemsReached) {
 			return nil, err
 		}
 
-		values = append(values, result...)
+		values = append(values, limiter.Filter(result)...)
 
 		return values, nil
 	})
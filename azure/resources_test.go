@@ -0,0 +1,159 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/opengovern/og-azure-describer/azure/describer"
+	"github.com/opengovern/og-util/pkg/describe/enums"
+)
+
+func TestDescribeBySubscription(t *testing.T) {
+	subscriptions := []string{"sub-1", "sub-2", "sub-3", "sub-4"}
+	describeFn := func(ctx context.Context, _ azcore.TokenCredential, subscription string, _ *describer.StreamSender) ([]describer.Resource, error) {
+		if subscription == "sub-2" {
+			return nil, fmt.Errorf("AuthorizationFailed")
+		}
+		return []describer.Resource{{ID: "/subscriptions/" + subscription + "/resource"}}, nil
+	}
+
+	t.Run("ContinueOnFailure", func(t *testing.T) {
+		ctx, fanOut := context.Background(), describer.SubscriptionFanOut{Concurrency: 2, ContinueOnFailure: true}
+		values, err := DescribeBySubscription(describeFn).DescribeResources(ctx, nil, describer.DescribeOptions{Subscriptions: subscriptions, TriggerType: enums.DescribeTriggerTypeManual, FanOut: fanOut}, nil)
+
+		var subscriptionErrs describer.SubscriptionErrors
+		if !errors.As(err, &subscriptionErrs) {
+			t.Fatalf("expected SubscriptionErrors, got %v", err)
+		}
+		if len(subscriptionErrs) != 1 || subscriptionErrs[0].SubscriptionID != "sub-2" {
+			t.Fatalf("unexpected subscription errors: %v", subscriptionErrs)
+		}
+		if len(values) != 3 {
+			t.Fatalf("expected 3 resources, got %d", len(values))
+		}
+		for i, subscription := range []string{"sub-1", "sub-3", "sub-4"} {
+			if values[i].SubscriptionID != subscription {
+				t.Errorf("resource %d: expected subscription %s, got %s", i, subscription, values[i].SubscriptionID)
+			}
+		}
+	})
+
+	t.Run("AbortOnFailure", func(t *testing.T) {
+		values, err := DescribeBySubscription(describeFn).DescribeResources(context.Background(), nil, describer.DescribeOptions{Subscriptions: subscriptions, TriggerType: enums.DescribeTriggerTypeManual}, nil)
+
+		var subscriptionErr describer.SubscriptionError
+		if !errors.As(err, &subscriptionErr) || subscriptionErr.SubscriptionID != "sub-2" {
+			t.Fatalf("expected SubscriptionError for sub-2, got %v", err)
+		}
+		if values != nil {
+			t.Fatalf("expected no resources, got %d", len(values))
+		}
+	})
+
+	t.Run("Concurrency", func(t *testing.T) {
+		var inFlight, maxInFlight int32
+		block := make(chan struct{})
+		var unblock sync.Once
+		fn := func(ctx context.Context, _ azcore.TokenCredential, subscription string, _ *describer.StreamSender) ([]describer.Resource, error) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				m := atomic.LoadInt32(&maxInFlight)
+				if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+					break
+				}
+			}
+			if n == 2 {
+				unblock.Do(func() { close(block) })
+			}
+			<-block
+			return nil, nil
+		}
+
+		ctx, fanOut := context.Background(), describer.SubscriptionFanOut{Concurrency: 2}
+		_, err := DescribeBySubscription(fn).DescribeResources(ctx, nil, describer.DescribeOptions{Subscriptions: subscriptions, TriggerType: enums.DescribeTriggerTypeManual, FanOut: fanOut}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if maxInFlight != 2 {
+			t.Fatalf("expected 2 subscriptions in flight, got %d", maxInFlight)
+		}
+	})
+	t.Run("ResultLimits", func(t *testing.T) {
+		fn := func(ctx context.Context, _ azcore.TokenCredential, subscription string, stream *describer.StreamSender) ([]describer.Resource, error) {
+			for _, rg := range []string{"rg-a", "rg-b", "RG-A"} {
+				if err := (*stream)(describer.Resource{ID: "/subscriptions/" + subscription + "/resourceGroups/" + rg + "/providers/x/y/z"}); err != nil {
+					return nil, err
+				}
+			}
+			return nil, nil
+		}
+
+		var sent []string
+		stream := describer.StreamSender(func(resource describer.Resource) error {
+			sent = append(sent, resource.ID)
+			return nil
+		})
+		opts := describer.DescribeOptions{
+			Subscriptions:  subscriptions,
+			FanOut:         describer.SubscriptionFanOut{Concurrency: 1},
+			ResourceGroups: []string{"rg-a"},
+			MaxItems:       3,
+		}
+		_, err := DescribeBySubscription(fn).DescribeResources(context.Background(), nil, opts, &stream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sent) != 3 {
+			t.Fatalf("expected 3 resources, got %v", sent)
+		}
+		for _, id := range sent {
+			if !strings.Contains(strings.ToLower(id), "/rg-a/") {
+				t.Errorf("unexpected resource group: %s", id)
+			}
+		}
+	})
+	t.Run("StreamErrors", func(t *testing.T) {
+		fn := func(ctx context.Context, _ azcore.TokenCredential, subscription string, stream *describer.StreamSender) ([]describer.Resource, error) {
+			return nil, (*stream)(describer.Resource{ID: "/subscriptions/" + subscription + "/resource"})
+		}
+
+		errConsumer := errors.New("consumer failed")
+		stream := describer.StreamSender(func(resource describer.Resource) error {
+			return errConsumer
+		})
+		opts := describer.DescribeOptions{Subscriptions: subscriptions, FanOut: describer.SubscriptionFanOut{Concurrency: 1}}
+		values, err := DescribeBySubscription(fn).DescribeResources(context.Background(), nil, opts, &stream)
+		if !errors.Is(err, errConsumer) {
+			t.Fatalf("expected the stream error, got %v", err)
+		}
+		if len(values) != 0 {
+			t.Fatalf("expected no resources, got %d", len(values))
+		}
+	})
+}
+
+func TestDescribeByResourceID(t *testing.T) {
+	d := DescribeByResourceID(func(ctx context.Context, _ azcore.TokenCredential, id *arm.ResourceID) (*describer.Resource, error) {
+		return &describer.Resource{ID: id.String(), Name: id.Name}, nil
+	})
+
+	resource, err := d.DescribeResource(context.Background(), nil, describer.DescribeOptions{}, "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.KeyVault/vaults/vault-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resource.SubscriptionID != "sub-1" || resource.Name != "vault-1" {
+		t.Errorf("unexpected resource: %+v", resource)
+	}
+
+	if _, err := d.DescribeResource(context.Background(), nil, describer.DescribeOptions{}, "vault-1"); err == nil {
+		t.Error("expected an error for an invalid resource id")
+	}
+}
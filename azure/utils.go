@@ -3,11 +3,11 @@ package azure
 import (
 	"context"
 	"fmt"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
 
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/authorization/mgmt/authorization"
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/subscription/mgmt/subscription"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/opengovern/og-azure-describer/azure/describer"
 )
 
 const (
@@ -16,16 +16,24 @@ const (
 )
 
 func CheckSPNAccessPermission(authConf AuthConfig) error {
+	return CheckSPNAccessPermissionContext(context.Background(), authConf)
+}
+
+func CheckSPNAccessPermissionContext(ctx context.Context, authConf AuthConfig) error {
 	authorizer, err := NewAuthorizerFromConfig(authConf)
 	if err != nil {
 		return err
 	}
+	cloud, err := CloudFromConfig(authConf)
+	if err != nil {
+		return err
+	}
 	// list subscriptions
-	client := subscription.NewSubscriptionsClient()
+	client := subscription.NewSubscriptionsClientWithBaseURI(cloud.ResourceManagerEndpoint())
 	client.Authorizer = authorizer
 	authorizer.WithAuthorization()
 
-	_, err = client.ListComplete(context.TODO())
+	_, err = client.ListComplete(ctx)
 	if err != nil {
 		return err
 	}
@@ -37,17 +45,29 @@ type EntraIdExtraData struct {
 }
 
 func CheckEntraIDPermission(authConf AuthConfig) (*EntraIdExtraData, error) {
-	creds, err := azidentity.NewClientSecretCredential(authConf.TenantID, authConf.ClientID, authConf.ClientSecret, nil)
+	return CheckEntraIDPermissionContext(context.Background(), authConf)
+}
+
+func CheckEntraIDPermissionContext(ctx context.Context, authConf AuthConfig) (*EntraIdExtraData, error) {
+	creds, err := NewTokenCredential(authConf, AuthEnv, "")
+	if err != nil {
+		return nil, err
+	}
+
+	cloud, err := CloudFromConfig(authConf)
 	if err != nil {
 		return nil, err
 	}
+	return checkEntraIDPermission(ctx, creds, cloud)
+}
 
-	graphClient, err := msgraphsdk.NewGraphServiceClientWithCredentials(creds, []string{"https://graph.microsoft.com/.default"})
+func checkEntraIDPermission(ctx context.Context, creds azcore.TokenCredential, cloud describer.Cloud) (*EntraIdExtraData, error) {
+	graphClient, err := describer.NewGraphServiceClient(describer.WithCloud(ctx, cloud), creds)
 	if err != nil {
 		return nil, err
 	}
 
-	orgs, err := graphClient.Organization().Get(context.TODO(), nil)
+	orgs, err := graphClient.Organization().Get(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +113,10 @@ func CheckEntraIDPermission(authConf AuthConfig) (*EntraIdExtraData, error) {
 }
 
 func CheckRole(authConf AuthConfig, subscriptionID string, roleDefinitionIDTemplate string) (bool, error) {
+	return CheckRoleContext(context.Background(), authConf, subscriptionID, roleDefinitionIDTemplate)
+}
+
+func CheckRoleContext(ctx context.Context, authConf AuthConfig, subscriptionID string, roleDefinitionIDTemplate string) (bool, error) {
 	if roleDefinitionIDTemplate == "" {
 		return false, fmt.Errorf("roleDefinitionIDTemplate is empty")
 	}
@@ -103,11 +127,16 @@ func CheckRole(authConf AuthConfig, subscriptionID string, roleDefinitionIDTempl
 		return false, err
 	}
 
-	client := authorization.NewRoleAssignmentsClient(subscriptionID)
+	cloud, err := CloudFromConfig(authConf)
+	if err != nil {
+		return false, err
+	}
+
+	client := authorization.NewRoleAssignmentsClientWithBaseURI(cloud.ResourceManagerEndpoint(), subscriptionID)
 	client.Authorizer = authorizer
 	authorizer.WithAuthorization()
 
-	it, err := client.ListComplete(context.TODO(), "")
+	it, err := client.ListComplete(ctx, "")
 	if err != nil {
 		return false, err
 	}
@@ -120,7 +149,7 @@ func CheckRole(authConf AuthConfig, subscriptionID string, roleDefinitionIDTempl
 		}
 
 		if it.NotDone() {
-			err := it.NextWithContext(context.TODO())
+			err := it.NextWithContext(ctx)
 			if err != nil {
 				return false, err
 			}
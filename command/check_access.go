@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/opengovern/og-azure-describer/azure"
+	"github.com/spf13/cobra"
+)
+
+var checkSubscriptionIDs []string
+var strict bool
+
+var checkAccessCmd = &cobra.Command{
+	Use:   "check-access",
+	Short: "Report the roles, Graph permissions and resource providers the describers need, and the resource types that would fail",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, err := azure.CheckAccess(cmd.Context(), authConfig, azure.AuthType(strings.ToUpper(authType)), authFile, checkSubscriptionIDs)
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+
+		failing := len(report.EntraID.FailingResourceTypes)
+		for _, s := range report.Subscriptions {
+			failing += len(s.FailingResourceTypes)
+		}
+		if strict && failing > 0 {
+			return fmt.Errorf("%d resource types would fail", failing)
+		}
+		return nil
+	},
+}
+
+func init() {
+	checkAccessCmd.Flags().StringSliceVar(&checkSubscriptionIDs, "subscriptionID", nil, "Subscriptions, repeated or comma separated, all the visible ones if none")
+	checkAccessCmd.Flags().BoolVar(&strict, "strict", false, "Fail if a resource type would fail")
+}
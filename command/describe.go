@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opengovern/og-azure-describer/azure"
+	"github.com/opengovern/og-azure-describer/azure/describer"
+	"github.com/opengovern/og-util/pkg/describe/enums"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	resourceTypes, subscriptionIDs, resourceGroups []string
+	output, outDir                                 string
+	fast, summarize                                bool
+	maxItems, concurrency                          int
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe",
+	Short: "Describe the resources of the given types, all the supported ones if none is given",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := outputFormat(output)
+		if _, err := newResourceWriter(format, io.Discard); err != nil {
+			return err
+		}
+
+		var types []string
+		for _, t := range resourceTypes {
+			rt, err := azure.GetResourceType(t)
+			if err != nil {
+				return err
+			}
+			types = append(types, rt.ResourceName)
+		}
+		types = selectResourceTypes(types, fast, summarize)
+		if len(types) == 0 {
+			return errors.New("no resource type to describe")
+		}
+		if len(subscriptionIDs) == 0 {
+			if id := os.Getenv("AZURE_SUBSCRIPTION_ID"); id != "" {
+				subscriptionIDs = []string{id}
+			}
+		}
+		if len(subscriptionIDs) == 0 {
+			ids, err := azure.ListSubscriptions(cmd.Context(), authConfig, azure.AuthType(strings.ToUpper(authType)), authFile)
+			if err != nil {
+				return fmt.Errorf("no --subscriptionID nor AZURE_SUBSCRIPTION_ID given: %w", err)
+			}
+			if len(ids) == 0 {
+				return errors.New("no --subscriptionID nor AZURE_SUBSCRIPTION_ID given and the credential sees no subscription")
+			}
+			subscriptionIDs = ids
+		}
+		if outDir != "" {
+			if err := os.MkdirAll(outDir, 0o755); err != nil {
+				return err
+			}
+		}
+
+		logger, err := zap.NewProduction()
+		if err != nil {
+			return err
+		}
+		defer logger.Sync()
+
+		ctx := describer.WithDescribeOptions(cmd.Context(), describer.DescribeOptions{
+			MaxItems:       maxItems,
+			ResourceGroups: resourceGroups,
+		})
+		// a failing subscription does not hide the resources of the others
+		ctx = describer.WithSubscriptionFanOut(ctx, describer.SubscriptionFanOut{Concurrency: concurrency, ContinueOnFailure: true})
+
+		var stdout resourceWriter
+		if outDir == "" {
+			stdout, err = newResourceWriter(format, os.Stdout)
+			if err != nil {
+				return err
+			}
+		}
+
+		failed := 0
+		for _, t := range types {
+			w := stdout
+			var file *os.File
+			if outDir != "" {
+				name := strings.ReplaceAll(strings.ToLower(t), "/", "_") + "." + format.extension()
+				file, err = os.Create(filepath.Join(outDir, name))
+				if err != nil {
+					return err
+				}
+				if w, err = newResourceWriter(format, file); err != nil {
+					file.Close()
+					return err
+				}
+			}
+
+			err := describeResourceType(ctx, logger, t, w)
+			if file != nil {
+				if closeErr := w.Close(); closeErr != nil && err == nil {
+					err = closeErr
+				}
+				if closeErr := file.Close(); closeErr != nil && err == nil {
+					err = closeErr
+				}
+			}
+			if err != nil {
+				failed++
+				fmt.Fprintf(os.Stderr, "%s: %v\n", t, err)
+			}
+		}
+		if stdout != nil {
+			if err := stdout.Close(); err != nil {
+				return err
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("failed to describe %d of %d resource types", failed, len(types))
+		}
+		return nil
+	},
+}
+
+func describeResourceType(ctx context.Context, logger *zap.Logger, resourceType string, w resourceWriter) error {
+	return azure.StreamResources(
+		ctx,
+		logger,
+		resourceType,
+		enums.DescribeTriggerTypeManual,
+		subscriptionIDs,
+		authConfig,
+		authType,
+		authFile,
+		w.Write,
+	)
+}
+
+func init() {
+	flags := describeCmd.Flags()
+	flags.StringSliceVarP(&resourceTypes, "resourceType", "t", nil, "Resource types, repeated or comma separated")
+	flags.StringSliceVar(&subscriptionIDs, "subscriptionID", nil, "Subscriptions, repeated or comma separated, AZURE_SUBSCRIPTION_ID or all the visible ones if none")
+	flags.StringSliceVar(&resourceGroups, "resourceGroup", nil, "Only the resources of these resource groups")
+	flags.StringVarP(&output, "output", "o", string(outputJSON), "Output: json, ndjson, csv or table")
+	flags.StringVar(&outDir, "out-dir", "", "Write the resources of each type to a file of this directory instead of stdout")
+	flags.StringVar(&outDir, "outDir", "", "Write the resources of each type to a file of this directory instead of stdout")
+	_ = flags.MarkDeprecated("outDir", "use --out-dir instead")
+	flags.BoolVar(&fast, "fast", false, "Only the fast discovery resource types")
+	flags.BoolVar(&summarize, "summarize", false, "Only the summarized resource types")
+	flags.IntVar(&maxItems, "maxItems", 0, "Stop after that many resources of each type, no limit if 0")
+	flags.IntVar(&concurrency, "concurrency", 0, "Subscriptions described in parallel")
+}
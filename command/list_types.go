@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/opengovern/og-azure-describer/azure"
+	"github.com/spf13/cobra"
+)
+
+var listFast, listSummarize bool
+var listOutput string
+
+var listTypesCmd = &cobra.Command{
+	Use:   "list-types",
+	Short: "List the supported resource types",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		types := selectResourceTypes(nil, listFast, listSummarize)
+		switch outputFormat(listOutput) {
+		case outputJSON:
+			return json.NewEncoder(os.Stdout).Encode(types)
+		case outputTable, outputNDJSON, outputCSV:
+			for _, t := range types {
+				fmt.Println(t)
+			}
+			return nil
+		default:
+			return fmt.Errorf("unsupported output %s", listOutput)
+		}
+	},
+}
+
+func init() {
+	listTypesCmd.Flags().BoolVar(&listFast, "fast", false, "Only the fast discovery resource types")
+	listTypesCmd.Flags().BoolVar(&listSummarize, "summarize", false, "Only the summarized resource types")
+	listTypesCmd.Flags().StringVarP(&listOutput, "output", "o", string(outputTable), "Output: json or table")
+}
+
+// selectResourceTypes returns the resource types among types, all of them if it is empty, that are fast
+// discovery or summarized if asked to.
+func selectResourceTypes(types []string, fast, summarize bool) []string {
+	if len(types) == 0 {
+		types = azure.ListResourceTypes()
+	}
+	var filters [][]string
+	if fast {
+		filters = append(filters, azure.ListFastDiscoveryResourceTypes())
+	}
+	if summarize {
+		filters = append(filters, azure.ListSummarizeResourceTypes())
+	}
+
+	var selected []string
+	for _, t := range types {
+		keep := true
+		for _, filter := range filters {
+			if !containsFold(filter, t) {
+				keep = false
+			}
+		}
+		if keep {
+			selected = append(selected, t)
+		}
+	}
+	return selected
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
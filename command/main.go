@@ -4,65 +4,37 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package main
 
 import (
-	"context"
-	"encoding/json"
-	"fmt"
+	"os"
+
 	"github.com/opengovern/og-azure-describer/azure"
-	"github.com/opengovern/og-util/pkg/describe/enums"
 	"github.com/spf13/cobra"
-	"go.uber.org/zap"
-	"os"
 )
 
-var (
-	resourceType, tenantID, clientID, clientSecret, subscriptionID string
-)
+var authConfig azure.AuthConfig
+var authType, authFile string
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "og-azure-describer",
-	Short: "opengovernance azure describer manual",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		resourceType = "Microsoft.Resources/groups"
-		subscriptionID = "710e21af-6987-4f5d-80a0-d2ef06f8645b"
-		logger, _ := zap.NewProduction()
-		output, err := azure.GetResources(
-			context.Background(),
-			logger,
-			resourceType,
-			enums.DescribeTriggerTypeManual,
-			[]string{subscriptionID},
-			azure.AuthConfig{
-				TenantID:            tenantID,
-				ClientID:            clientID,
-				ClientSecret:        clientSecret,
-				CertificatePath:     "",
-				CertificatePassword: "",
-				Username:            "",
-				Password:            "",
-			},
-			string(azure.AuthEnv),
-			"",
-			nil,
-		)
-		if err != nil {
-			return fmt.Errorf("Azure: %w", err)
-		}
-		js, err := json.Marshal(output)
-		if err != nil {
-			return err
-		}
-		fmt.Println(string(js))
-		return nil
-	},
+	Use:          "og-azure-cli",
+	Short:        "opengovernance azure describer manual",
+	SilenceUsage: true,
 }
 
 func init() {
-	rootCmd.Flags().StringVarP(&resourceType, "resourceType", "t", "", "Resource type")
-	rootCmd.Flags().StringVarP(&tenantID, "tenantID", "", "", "TenantID")
-	rootCmd.Flags().StringVarP(&clientID, "clientID", "", "", "ClientID")
-	rootCmd.Flags().StringVarP(&clientSecret, "clientSecret", "", "", "ClientSecret")
-	rootCmd.Flags().StringVarP(&subscriptionID, "subscriptionID", "", "", "SubscriptionID")
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&authType, "authType", string(azure.AuthEnv), "Authentication: env, file, cli, managed_identity or workload_identity")
+	flags.StringVar(&authFile, "authFile", "", "SDK auth file of the file authentication, AZURE_AUTH_LOCATION if empty")
+	flags.StringVar(&authConfig.TenantID, "tenantID", "", "TenantID")
+	flags.StringVar(&authConfig.ClientID, "clientID", "", "ClientID")
+	flags.StringVar(&authConfig.ClientSecret, "clientSecret", "", "ClientSecret")
+	flags.StringVar(&authConfig.CertificatePath, "certificatePath", "", "Client certificate of the service principal")
+	flags.StringVar(&authConfig.CertificatePassword, "certificatePassword", "", "Password of the client certificate")
+	flags.StringVar(&authConfig.Username, "username", "", "Username of the user authentication")
+	flags.StringVar(&authConfig.Password, "password", "", "Password of the user authentication")
+	flags.StringVar(&authConfig.FederatedTokenFile, "federatedTokenFile", "", "Token file of the workload identity authentication")
+	flags.StringVar(&authConfig.EnvironmentName, "environment", "", "Azure cloud, e.g. AzureUSGovernmentCloud, AzurePublicCloud if empty")
+
+	rootCmd.AddCommand(listTypesCmd, describeCmd, checkAccessCmd)
 }
 
 func main() {
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/opengovern/og-azure-describer/azure/describer"
+)
+
+type outputFormat string
+
+const (
+	outputJSON   outputFormat = "json"
+	outputNDJSON outputFormat = "ndjson"
+	outputCSV    outputFormat = "csv"
+	outputTable  outputFormat = "table"
+)
+
+// extension is the extension of the files written to --outDir.
+func (f outputFormat) extension() string {
+	if f == outputTable {
+		return "txt"
+	}
+	return string(f)
+}
+
+// resourceWriter writes the resources one by one as they are described.
+type resourceWriter interface {
+	Write(resource describer.Resource) error
+	// Close ends the output, e.g. closes the JSON array, it does not close the underlying writer.
+	Close() error
+}
+
+func newResourceWriter(format outputFormat, w io.Writer) (resourceWriter, error) {
+	switch format {
+	case outputJSON:
+		return &jsonWriter{w: w}, nil
+	case outputNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case outputCSV:
+		cw := csv.NewWriter(w)
+		return &csvWriter{w: cw}, cw.Write(append(resourceColumns, "description"))
+	case outputTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		_, err := fmt.Fprintln(tw, strings.ToUpper(strings.Join(resourceColumns, "\t")))
+		return &tableWriter{w: tw}, err
+	default:
+		return nil, fmt.Errorf("unsupported output %s", format)
+	}
+}
+
+var resourceColumns = []string{"id", "name", "type", "resource_group", "location", "subscription_id"}
+
+func resourceRow(resource describer.Resource) []string {
+	return []string{resource.ID, resource.Name, resource.Type, resource.ResourceGroup, resource.Location, resource.SubscriptionID}
+}
+
+// jsonWriter writes a JSON array, streamed rather than marshalled at once.
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonWriter) Write(resource describer.Resource) error {
+	b, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if j.count == 0 {
+		sep = "[\n"
+	}
+	j.count++
+	if _, err := io.WriteString(j.w, sep); err != nil {
+		return err
+	}
+	_, err = j.w.Write(b)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	end := "\n]\n"
+	if j.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(j.w, end)
+	return err
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(resource describer.Resource) error {
+	return n.enc.Encode(resource)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) Write(resource describer.Resource) error {
+	description, err := json.Marshal(resource.Description)
+	if err != nil {
+		return err
+	}
+	return c.w.Write(append(resourceRow(resource), string(description)))
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// tableWriter aligns the columns, it holds the rows until it is closed.
+type tableWriter struct {
+	w *tabwriter.Writer
+}
+
+func (t *tableWriter) Write(resource describer.Resource) error {
+	row := resourceRow(resource)
+	for i := range row {
+		// the cells must not break the alignment
+		row[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(row[i])
+	}
+	_, err := fmt.Fprintln(t.w, strings.Join(row, "\t"))
+	return err
+}
+
+func (t *tableWriter) Close() error {
+	return t.w.Flush()
+}
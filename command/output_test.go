@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+
+	"github.com/opengovern/og-azure-describer/azure/describer"
+)
+
+func TestResourceWriters(t *testing.T) {
+	resources := []describer.Resource{
+		{ID: "/subscriptions/s/resourceGroups/rg/providers/p/t/a", Name: "a", Description: map[string]string{"k": "v"}},
+		{ID: "/subscriptions/s/resourceGroups/rg/providers/p/t/b", Name: "b\tc"},
+	}
+	write := func(format outputFormat, resources []describer.Resource) []byte {
+		var buf bytes.Buffer
+		w, err := newResourceWriter(format, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range resources {
+			if err := w.Write(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	for _, rs := range [][]describer.Resource{nil, resources} {
+		var decoded []describer.Resource
+		if err := json.Unmarshal(write(outputJSON, rs), &decoded); err != nil || len(decoded) != len(rs) {
+			t.Errorf("json: expected %d resources, got %d: %v", len(rs), len(decoded), err)
+		}
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(write(outputCSV, resources))).ReadAll()
+	if err != nil || len(records) != 3 || records[1][6] != `{"k":"v"}` {
+		t.Errorf("csv: unexpected records %v: %v", records, err)
+	}
+
+	if lines := bytes.Count(write(outputTable, resources), []byte("\n")); lines != 3 {
+		t.Errorf("table: expected 3 lines, got %d", lines)
+	}
+}
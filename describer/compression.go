@@ -0,0 +1,103 @@
+package describer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip"
+)
+
+// Compression is the encoding of the ingest requests, the sinks send them uncompressed by default.
+type Compression string
+
+const (
+	CompressionNone Compression = ""
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+const SinkCompressionEnv = "DESCRIBER_SINK_COMPRESSION"
+
+func ParseCompression(s string) (Compression, error) {
+	switch c := Compression(s); c {
+	case CompressionNone, "none":
+		return CompressionNone, nil
+	case CompressionGzip, CompressionZstd:
+		return c, nil
+	default:
+		return CompressionNone, fmt.Errorf("unsupported compression %s", s)
+	}
+}
+
+// compress encodes the body of an HTTP ingest request, it is sent with the Content-Encoding c.
+func compress(c Compression, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	switch c {
+	case CompressionNone:
+		return body, nil
+	case CompressionGzip:
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(body); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+	case CompressionZstd:
+		enc := zstdEncoders.Get().(*zstd.Encoder)
+		defer zstdEncoders.Put(enc)
+		return enc.EncodeAll(body, make([]byte, 0, len(body)/4)), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %s", c)
+	}
+	return buf.Bytes(), nil
+}
+
+// the encoders are expensive to create, they are reused across the requests
+var zstdEncoders = sync.Pool{
+	New: func() any {
+		enc, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return enc
+	},
+}
+
+func init() {
+	encoding.RegisterCompressor(zstdCompressor{})
+}
+
+// zstdCompressor is the gRPC compressor of CompressionZstd, the server has to register one with the same name.
+type zstdCompressor struct{}
+
+func (zstdCompressor) Name() string {
+	return string(CompressionZstd)
+}
+
+func (zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	enc := zstdEncoders.Get().(*zstd.Encoder)
+	enc.Reset(w)
+	return &pooledZstdWriter{Encoder: enc}, nil
+}
+
+func (zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return dec.IOReadCloser(), nil
+}
+
+// pooledZstdWriter returns the encoder to the pool once the message is written.
+type pooledZstdWriter struct {
+	*zstd.Encoder
+}
+
+func (w *pooledZstdWriter) Close() error {
+	err := w.Encoder.Close()
+	zstdEncoders.Put(w.Encoder)
+	return err
+}
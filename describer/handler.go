@@ -2,89 +2,93 @@ package describer
 
 import (
 	"context"
-	"crypto/tls"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/golang-jwt/jwt/v5"
-	"google.golang.org/grpc/credentials/insecure"
-	"os"
 	"time"
 
+	azureDescriber "github.com/opengovern/og-azure-describer/azure/describer"
 	"github.com/opengovern/og-util/pkg/describe"
 	"github.com/opengovern/og-util/pkg/vault"
 	"github.com/opengovern/og-util/proto/src/golang"
 	"go.uber.org/zap"
 	"golang.org/x/oauth2"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/oauth"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 const (
 	DescribeResourceJobFailed    string = "FAILED"
 	DescribeResourceJobSucceeded string = "SUCCEEDED"
+	DescribeResourceJobPartial   string = "PARTIAL"
 )
 
-func getJWTAuthToken() (string, error) {
-	privateKey, ok := os.LookupEnv("JWT_PRIVATE_KEY")
-	if !ok {
-		return "", fmt.Errorf("JWT_PRIVATE_KEY not set")
-	}
-
-	privateKeyBytes, err := base64.StdEncoding.DecodeString(privateKey)
-	if err != nil {
-		return "", fmt.Errorf("JWT_PRIVATE_KEY not base64 encoded")
-	}
-
-	pk, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyBytes)
-	if err != nil {
-		return "", fmt.Errorf("JWT_PRIVATE_KEY not valid")
-	}
-
-	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
-		"https://app.kaytu.io/email": "lambda-worker@kaytu.io",
-	}).SignedString(pk)
-	if err != nil {
-		return "", fmt.Errorf("JWT token generation failed %v", err)
-	}
-	return token, nil
-}
-
 type TriggeredBy string
 
 const (
 	TriggeredByAWSLambda     TriggeredBy = "aws-lambda"
 	TriggeredByAzureFunction TriggeredBy = "azure-function"
 	TriggeredByLocal         TriggeredBy = "local"
+	TriggeredByRabbitMQ      TriggeredBy = "rabbitmq"
 )
 
+// JobResult is the outcome of a describe job delivered to the scheduler by RunDescribeJob.
+type JobResult struct {
+	Status string
+	// ErrorCode is the error code returned by Azure, e.g. AuthorizationFailed, UnknownFailure if there is none.
+	ErrorCode string
+	// ErrorCategory is the stable category of the error, see azure/describer.ErrorCategory. The scheduler only
+	// receives it in the error message.
+	ErrorCategory string
+	Error         string
+	// Err is the error the job failed with, nil if it succeeded.
+	Err error
+}
+
+// Retryable reports whether running the job again may succeed, i.e. it failed with a transient or throttling
+// error. The PARTIAL jobs are not retried, their resources were ingested.
+func (r JobResult) Retryable() bool {
+	return r.Status == DescribeResourceJobFailed && azureDescriber.ErrorCategory(r.ErrorCategory).Retryable()
+}
+
 // DescribeHandler
 // TriggeredBy is not used for now but might be relevant in the future
-func DescribeHandler(ctx context.Context, logger *zap.Logger, _ TriggeredBy, input describe.DescribeWorkerInput) error {
-	var err error
+func DescribeHandler(ctx context.Context, logger *zap.Logger, triggeredBy TriggeredBy, input describe.DescribeWorkerInput) error {
+	_, err := RunDescribeJob(ctx, logger, triggeredBy, input)
+	return err
+}
 
-	var token string
+// RunDescribeJob runs the job and delivers its result to the scheduler. It returns an error if the job could not
+// be started or its result could not be delivered, the failures of the job itself are in the JobResult.
+func RunDescribeJob(ctx context.Context, logger *zap.Logger, _ TriggeredBy, input describe.DescribeWorkerInput) (JobResult, error) {
+	vaultSc, err := NewVaultSourceConfig(ctx, logger, input.VaultConfig)
+	if err != nil {
+		return JobResult{}, err
+	}
+
+	// the tokens are short-lived and renewed for the long-running jobs
+	var jobTokens, sinkTokens oauth2.TokenSource
 	if input.EndpointAuth {
-		token, err = getJWTAuthToken()
+		key, err := LoadJWTSigningKey(ctx, logger, input.VaultConfig, vaultSc)
 		if err != nil {
-			return fmt.Errorf("failed to get JWT token: %w", err)
+			return JobResult{}, fmt.Errorf("failed to get JWT signing key: %w", err)
 		}
+		jobTokens = NewJWTTokenSource(key, input.JobEndpoint, input.DescribeJob.JobID)
+		sinkTokens = NewJWTTokenSource(key, input.DeliverEndpoint, input.DescribeJob.JobID)
 	}
 
 	var client golang.DescribeServiceClient
 	grpcCtx := metadata.NewOutgoingContext(context.Background(), metadata.New(map[string]string{}))
-	var opts []grpc.DialOption
+	transportCreds, err := GRPCTLSConfigFromEnv().TransportCredentials(input.EndpointAuth)
+	if err != nil {
+		return JobResult{}, fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(transportCreds)}
 	if input.EndpointAuth {
-		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})))
-		opts = append(opts, grpc.WithPerRPCCredentials(oauth.TokenSource{
-			TokenSource: oauth2.StaticTokenSource(&oauth2.Token{
-				AccessToken: token,
-			}),
-		}))
-	} else {
-		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		opts = append(opts, grpc.WithPerRPCCredentials(oauth.TokenSource{TokenSource: jobTokens}))
 	}
 	for retry := 0; retry < 5; retry++ {
 		conn, err := grpc.NewClient(
@@ -94,7 +98,7 @@ func DescribeHandler(ctx context.Context, logger *zap.Logger, _ TriggeredBy, inp
 		if err != nil {
 			logger.Error("[result delivery] connection failure:", zap.Error(err))
 			if retry == 4 {
-				return err
+				return JobResult{}, err
 			}
 			time.Sleep(1 * time.Second)
 			continue
@@ -110,7 +114,7 @@ func DescribeHandler(ctx context.Context, logger *zap.Logger, _ TriggeredBy, inp
 		if err != nil {
 			logger.Error("[result delivery] set in progress failure:", zap.Error(err))
 			if retry == 4 {
-				return err
+				return JobResult{}, err
 			}
 			time.Sleep(1 * time.Second)
 			continue
@@ -118,110 +122,22 @@ func DescribeHandler(ctx context.Context, logger *zap.Logger, _ TriggeredBy, inp
 		break
 	}
 
-	var vaultSc vault.VaultSourceConfig
-	switch input.VaultConfig.Provider {
-	case vault.AwsKMS:
-		vaultSc, err = vault.NewKMSVaultSourceConfig(ctx, input.VaultConfig.Aws, input.VaultConfig.KeyId)
-		if err != nil {
-			return fmt.Errorf("failed to initialize KMS vault: %w", err)
-		}
-	case vault.AzureKeyVault:
-		vaultSc, err = vault.NewAzureVaultClient(ctx, logger, input.VaultConfig.Azure, input.VaultConfig.KeyId)
-		if err != nil {
-			return fmt.Errorf("failed to initialize Azure vault: %w", err)
-		}
-	case vault.HashiCorpVault:
-		vaultSc, err = vault.NewHashiCorpVaultClient(ctx, logger, input.VaultConfig.HashiCorp, input.VaultConfig.KeyId)
-		if err != nil {
-			return fmt.Errorf("failed to initialize HashiCorp vault: %w", err)
-		}
-	}
-
 	resourceIds, err := Do(
 		ctx,
 		vaultSc,
 		logger,
 		input.DescribeJob,
-		input.DeliverEndpoint,
-		token,
-		input.IngestionPipelineEndpoint,
-		input.UseOpenSearch,
+		SinkConfigFromInput(input, sinkTokens),
 	)
 
-	errMsg := ""
-	errCode := ""
-	status := DescribeResourceJobSucceeded
-	if err != nil {
-		//errMsg = err.Error()
-		//var detailedErr autorest.DetailedError
-		//if errors.As(err, &detailedErr) {
-		//	errCode = fmt.Sprintf("%v", detailedErr.StatusCode)
-		//}
-		//
-		//var validationErr validation.Error
-		//if errors.As(err, &validationErr) {
-		//	errCode = "ValidationError"
-		//}
-		//
-		//if errCode == "" {
-		//	if strings.Contains(err.Error(), "InvalidAuthenticationToken") {
-		//		errCode = "InvalidAuthenticationToken"
-		//	}
-		//}
-
-		errorString := err.Error()
-		jsonStart := 0
-		jsonEnd := len(errorString) - 1
-		for i := 0; i < len(errorString)-1; i++ {
-			if errorString[i] == '{' {
-				jsonStart = i
-				break
-			}
-		}
-		for i := len(errorString) - 1; i > 0; i-- {
-			if errorString[i] == '}' {
-				jsonEnd = i + 1
-				break
-			}
-		}
-
-		jsonString := errorString[jsonStart:jsonEnd]
-		var jsonData map[string]interface{}
-		err := json.Unmarshal([]byte(jsonString), &jsonData)
-		if err != nil || jsonData == nil {
-			errMsg = errorString
-			errCode = "UnknownFailure"
-		} else {
-			if errorData, ok := jsonData["error"]; ok {
-				if v, ok := errorData.(map[string]interface{}); ok {
-					if code, ok := v["code"].(string); ok {
-						errCode = code
-					} else {
-						errCode = "unknown"
-					}
-					if msg, ok := v["message"].(string); ok {
-						errMsg = msg
-					} else {
-						errMsg = fmt.Sprintf("ErrMsg= %v", v)
-					}
-				} else {
-					errCode = "unknown"
-					errMsg = fmt.Sprintf("ErrorData= %v", errorData)
-				}
-			} else {
-				errMsg = errorString
-				errCode = "UnknownFailure"
-			}
-		}
-		status = DescribeResourceJobFailed
-	}
+	result := jobResult(resourceIds, err)
 
 	for retry := 0; retry < 5; retry++ {
 		_, err = client.DeliverResult(grpcCtx, &golang.DeliverResultRequest{
 			JobId:     uint32(input.DescribeJob.JobID),
-			Status:    status,
-			Error:     errMsg,
-			ErrorCode: errCode,
+			Status:    result.Status,
+			Error:     result.Error,
+			ErrorCode: result.ErrorCode,
 			DescribeJob: &golang.DescribeJob{
 				JobId:        uint32(input.DescribeJob.JobID),
 				ResourceType: input.DescribeJob.ResourceType,
@@ -237,12 +153,131 @@ func DescribeHandler(ctx context.Context, logger *zap.Logger, _ TriggeredBy, inp
 		})
 		if err != nil {
 			logger.Error("[result delivery] rpc failed:", zap.Error(err))
+			if retry == 4 {
+				return result, fmt.Errorf("deliver the result of job %d: %w", input.DescribeJob.JobID, err)
+			}
 			time.Sleep(1 * time.Second)
 			continue
 		}
 		break
 	}
 
-	logger.Info("job done", zap.Uint("jobID", input.DescribeJob.JobID))
-	return nil
+	logger.Info("job done", zap.Uint("jobID", input.DescribeJob.JobID), zap.String("status", result.Status))
+	return result, nil
+}
+
+// NewVaultSourceConfig returns the vault decrypting the credentials of the jobs, nil if no provider is configured.
+func NewVaultSourceConfig(ctx context.Context, logger *zap.Logger, cfg vault.Config) (vault.VaultSourceConfig, error) {
+	switch cfg.Provider {
+	case vault.AwsKMS:
+		vaultSc, err := vault.NewKMSVaultSourceConfig(ctx, cfg.Aws, cfg.KeyId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize KMS vault: %w", err)
+		}
+		return vaultSc, nil
+	case vault.AzureKeyVault:
+		vaultSc, err := vault.NewAzureVaultClient(ctx, logger, cfg.Azure, cfg.KeyId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Azure vault: %w", err)
+		}
+		return vaultSc, nil
+	case vault.HashiCorpVault:
+		vaultSc, err := vault.NewHashiCorpVaultClient(ctx, logger, cfg.HashiCorp, cfg.KeyId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize HashiCorp vault: %w", err)
+		}
+		return vaultSc, nil
+	}
+	return nil, nil
+}
+
+// jobResult returns the outcome of a job that described resourceIDs and ended with err. A job whose scopes all
+// failed is FAILED, with the failed scopes in its report.
+func jobResult(resourceIDs []string, err error) JobResult {
+	result := JobResult{Status: DescribeResourceJobSucceeded, Err: err}
+	var partialErr *azureDescriber.PartialError
+	if errors.As(err, &partialErr) {
+		result.Status = DescribeResourceJobPartial
+		if len(resourceIDs) == 0 {
+			result.Status = DescribeResourceJobFailed
+		}
+		result.ErrorCode, result.ErrorCategory, result.Error = partialFailureReport(partialErr, len(resourceIDs))
+	} else if err != nil {
+		result.Status = DescribeResourceJobFailed
+		result.ErrorCode, result.ErrorCategory, result.Error = errorCodeAndMessage(err)
+	}
+	return result
+}
+
+// IsRetryableError reports whether running again a job DescribeHandler failed with may succeed, i.e. the
+// scheduler or Azure were unavailable.
+func IsRetryableError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	}
+	return azureDescriber.ClassifyError(err).Category.Retryable()
+}
+
+// errorCodeAndMessage classifies the error returned by a describer. It returns the Azure error code the scheduler
+// has always received, the stable ErrorCategory deciding whether the job is retried and the message.
+func errorCodeAndMessage(err error) (string, string, string) {
+	c := azureDescriber.ClassifyError(err)
+	return azureErrorCode(c), string(c.Category), classifiedErrorMessage(c)
+}
+
+// azureErrorCode returns the Azure error code of c, UnknownFailure if the error did not come from Azure.
+func azureErrorCode(c azureDescriber.ClassifiedError) string {
+	if c.Code == "" {
+		return "UnknownFailure"
+	}
+	return c.Code
+}
+
+func classifiedErrorMessage(c azureDescriber.ClassifiedError) string {
+	msg := c.Message
+	if c.Code != "" {
+		msg = fmt.Sprintf("%s: %s", c.Code, msg)
+	}
+	msg = fmt.Sprintf("%s (category: %s)", msg, c.Category)
+	if c.StatusCode != 0 {
+		msg = fmt.Sprintf("%s (status: %d)", msg, c.StatusCode)
+	}
+	if c.RequestID != "" {
+		msg = fmt.Sprintf("%s (request id: %s)", msg, c.RequestID)
+	}
+	return msg
+}
+
+// partialFailureReport builds the error code, the error category and the JSON encoded PartialFailureReport
+// delivered with a PARTIAL job, or a FAILED one that ingested no resource. The code and the category are the
+// ones of the first failed scope.
+func partialFailureReport(partialErr *azureDescriber.PartialError, ingestedResourceCount int) (string, string, string) {
+	report := PartialFailureReport{
+		IngestedResourceCount: ingestedResourceCount,
+	}
+	for _, scope := range partialErr.FailedScopes {
+		c := azureDescriber.ClassifyError(scope.Err)
+		report.FailedScopes = append(report.FailedScopes, FailedScopeReport{
+			Type:          string(scope.Type),
+			ID:            scope.ID,
+			ErrorCategory: string(c.Category),
+			ErrorCode:     c.Code,
+			Error:         c.Message,
+			StatusCode:    c.StatusCode,
+			RequestID:     c.RequestID,
+		})
+	}
+
+	errCode, category := DescribeResourceJobPartial, ""
+	if len(partialErr.FailedScopes) > 0 {
+		c := azureDescriber.ClassifyError(partialErr.FailedScopes[0].Err)
+		errCode, category = azureErrorCode(c), string(c.Category)
+	}
+
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return errCode, category, partialErr.Error()
+	}
+	return errCode, category, string(reportJSON)
 }
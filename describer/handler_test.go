@@ -0,0 +1,66 @@
+package describer
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	azureDescriber "github.com/opengovern/og-azure-describer/azure/describer"
+)
+
+func TestJobResult(t *testing.T) {
+	partialErr := &azureDescriber.PartialError{FailedScopes: []azureDescriber.FailedScope{
+		{Type: azureDescriber.ScopeTypeResource, ID: "/subscriptions/s/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/v", Err: errors.New(`{"error":{"code":"AuthorizationFailed","message":"denied"}}`)},
+	}}
+
+	tests := []struct {
+		name        string
+		resourceIDs []string
+		err         error
+		status      string
+		errCode     string
+		category    string
+		scopes      int
+	}{
+		{name: "succeeded", resourceIDs: []string{"a"}, status: DescribeResourceJobSucceeded},
+		{name: "partial", resourceIDs: []string{"a"}, err: partialErr, status: DescribeResourceJobPartial, errCode: "AuthorizationFailed", category: "Forbidden", scopes: 1},
+		{name: "all scopes failed", err: partialErr, status: DescribeResourceJobFailed, errCode: "AuthorizationFailed", category: "Forbidden", scopes: 1},
+		{name: "failed", err: errors.New("boom"), status: DescribeResourceJobFailed, errCode: "UnknownFailure", category: "Internal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := jobResult(tt.resourceIDs, tt.err)
+			if result.Status != tt.status || result.ErrorCode != tt.errCode || result.ErrorCategory != tt.category {
+				t.Fatalf("got %s/%s/%s, want %s/%s/%s", result.Status, result.ErrorCode, result.ErrorCategory, tt.status, tt.errCode, tt.category)
+			}
+			if tt.scopes == 0 {
+				return
+			}
+			var report PartialFailureReport
+			if err := json.Unmarshal([]byte(result.Error), &report); err != nil {
+				t.Fatalf("error is not a failure report: %v", err)
+			}
+			if len(report.FailedScopes) != tt.scopes || report.IngestedResourceCount != len(tt.resourceIDs) {
+				t.Errorf("unexpected report %+v", report)
+			}
+		})
+	}
+}
+
+func TestJobResultRetryable(t *testing.T) {
+	tests := []struct {
+		result JobResult
+		want   bool
+	}{
+		{JobResult{Status: DescribeResourceJobSucceeded}, false},
+		{JobResult{Status: DescribeResourceJobFailed, ErrorCategory: string(azureDescriber.ErrorCategoryThrottled)}, true},
+		{JobResult{Status: DescribeResourceJobFailed, ErrorCategory: string(azureDescriber.ErrorCategoryTransient)}, true},
+		{JobResult{Status: DescribeResourceJobFailed, ErrorCategory: string(azureDescriber.ErrorCategoryForbidden)}, false},
+		{JobResult{Status: DescribeResourceJobPartial, ErrorCategory: string(azureDescriber.ErrorCategoryThrottled)}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.result.Retryable(); got != tt.want {
+			t.Errorf("%s/%s: got %v, want %v", tt.result.Status, tt.result.ErrorCategory, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package describer
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/opengovern/og-util/pkg/vault"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+const (
+	JWTPrivateKeyEnv = "JWT_PRIVATE_KEY"
+	// JWTPrivateKeySecretIDEnv is the id of the vault secret holding the signing key, for the Azure Key Vault
+	// and HashiCorp Vault providers.
+	JWTPrivateKeySecretIDEnv = "JWT_PRIVATE_KEY_SECRET_ID"
+	// JWTPrivateKeyCipherTextEnv is the signing key encrypted with the vault of the job, for the AWS KMS provider.
+	// The decrypted object holds the key in its private_key field.
+	JWTPrivateKeyCipherTextEnv = "JWT_PRIVATE_KEY_CIPHERTEXT"
+	JWTAudienceEnv             = "JWT_AUDIENCE"
+
+	JWTTokenTTL time.Duration = 5 * time.Minute
+	// jwtEarlyExpiry renews the tokens before they expire so that a call in flight does not get rejected.
+	jwtEarlyExpiry = 30 * time.Second
+
+	jwtEmail = "lambda-worker@kaytu.io"
+)
+
+// JWTTokenSource issues short-lived tokens authenticating the describer to the scheduler, bound to a job and an audience.
+type JWTTokenSource struct {
+	key      *rsa.PrivateKey
+	audience string
+	jobID    uint
+	ttl      time.Duration
+}
+
+// NewJWTTokenSource returns a source of tokens for the audience, renewed before they expire. The audience is
+// usually the endpoint called with the tokens, JWT_AUDIENCE overrides it.
+func NewJWTTokenSource(key *rsa.PrivateKey, audience string, jobID uint) oauth2.TokenSource {
+	if aud := os.Getenv(JWTAudienceEnv); aud != "" {
+		audience = aud
+	}
+	src := &JWTTokenSource{key: key, audience: audience, jobID: jobID, ttl: JWTTokenTTL}
+	return oauth2.ReuseTokenSourceWithExpiry(nil, src, jwtEarlyExpiry)
+}
+
+func (s *JWTTokenSource) Token() (*oauth2.Token, error) {
+	now := time.Now()
+	expiry := now.Add(s.ttl)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"https://app.kaytu.io/email": jwtEmail,
+		"aud":                        s.audience,
+		"iat":                        now.Unix(),
+		"nbf":                        now.Unix(),
+		"exp":                        expiry.Unix(),
+		"job_id":                     fmt.Sprintf("%d", s.jobID),
+	}).SignedString(s.key)
+	if err != nil {
+		return nil, fmt.Errorf("JWT token generation failed %v", err)
+	}
+	return &oauth2.Token{AccessToken: token, TokenType: "Bearer", Expiry: expiry}, nil
+}
+
+// LoadJWTSigningKey loads the key signing the tokens from JWT_PRIVATE_KEY, or from the vault of the job if
+// JWT_PRIVATE_KEY_SECRET_ID or JWT_PRIVATE_KEY_CIPHERTEXT is set.
+func LoadJWTSigningKey(ctx context.Context, logger *zap.Logger, vaultConfig vault.Config, vaultSc vault.VaultSourceConfig) (*rsa.PrivateKey, error) {
+	var privateKey string
+	switch {
+	case os.Getenv(JWTPrivateKeySecretIDEnv) != "":
+		var handler vault.VaultSecretHandler
+		var err error
+		switch vaultConfig.Provider {
+		case vault.AzureKeyVault:
+			handler, err = vault.NewAzureVaultSecretHandler(logger, vaultConfig.Azure)
+		case vault.HashiCorpVault:
+			handler, err = vault.NewHashiCorpVaultSecretHandler(ctx, logger, vaultConfig.HashiCorp)
+		default:
+			return nil, fmt.Errorf("%s is not supported by the %s vault", JWTPrivateKeySecretIDEnv, vaultConfig.Provider)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize vault secret handler: %w", err)
+		}
+		privateKey, err = handler.GetSecret(ctx, os.Getenv(JWTPrivateKeySecretIDEnv))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get JWT private key from vault: %w", err)
+		}
+	case os.Getenv(JWTPrivateKeyCipherTextEnv) != "":
+		if vaultSc == nil {
+			return nil, fmt.Errorf("%s requires a vault", JWTPrivateKeyCipherTextEnv)
+		}
+		secret, err := vaultSc.Decrypt(ctx, os.Getenv(JWTPrivateKeyCipherTextEnv))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt JWT private key: %w", err)
+		}
+		privateKey, _ = secret["private_key"].(string)
+		if privateKey == "" {
+			return nil, fmt.Errorf("decrypted JWT private key has no private_key field")
+		}
+	default:
+		var ok bool
+		privateKey, ok = os.LookupEnv(JWTPrivateKeyEnv)
+		if !ok {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY not set")
+		}
+	}
+	return parseJWTSigningKey(privateKey)
+}
+
+// parseJWTSigningKey parses a PEM encoded RSA key, base64 encoded or not.
+func parseJWTSigningKey(privateKey string) (*rsa.PrivateKey, error) {
+	privateKeyBytes := []byte(privateKey)
+	if !strings.HasPrefix(strings.TrimSpace(privateKey), "-----BEGIN") {
+		var err error
+		privateKeyBytes, err = base64.StdEncoding.DecodeString(strings.TrimSpace(privateKey))
+		if err != nil {
+			return nil, errors.New("JWT private key not base64 encoded")
+		}
+	}
+
+	pk, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyBytes)
+	if err != nil {
+		return nil, errors.New("JWT private key not valid")
+	}
+	return pk, nil
+}
@@ -0,0 +1,40 @@
+package describer
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestJWTTokenSource(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := NewJWTTokenSource(key, "scheduler:50051", 42)
+	token, err := tokens.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := tokens.Token(); again.AccessToken != token.AccessToken {
+		t.Error("expected the token to be reused until it expires")
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(token.AccessToken, claims, func(*jwt.Token) (interface{}, error) {
+		return &key.PublicKey, nil
+	}, jwt.WithAudience("scheduler:50051"), jwt.WithExpirationRequired())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims["job_id"] != "42" {
+		t.Errorf("unexpected job id claim %v", claims["job_id"])
+	}
+	if exp, _ := claims.GetExpirationTime(); exp == nil || exp.After(time.Now().Add(JWTTokenTTL+time.Minute)) {
+		t.Errorf("unexpected expiration %v", exp)
+	}
+}
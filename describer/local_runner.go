@@ -0,0 +1,65 @@
+package describer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/opengovern/og-util/pkg/describe"
+	"github.com/opengovern/og-util/pkg/source"
+	"github.com/opengovern/og-util/pkg/vault"
+	"go.uber.org/zap"
+)
+
+// JobStatusRecord is the outcome of a job run by RunLocalJob, what DescribeHandler delivers to the scheduler.
+type JobStatusRecord struct {
+	JobID         uint      `json:"job_id"`
+	ResourceType  string    `json:"resource_type"`
+	AccountID     string    `json:"account_id"`
+	Status        string    `json:"status"`
+	ErrorCode     string    `json:"error_code,omitempty"`
+	ErrorCategory string    `json:"error_category,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	ResourceCount int       `json:"resource_count"`
+	ResourcesFile string    `json:"resources_file"`
+	StartedAt     time.Time `json:"started_at"`
+	FinishedAt    time.Time `json:"finished_at"`
+}
+
+// RunLocalJob runs a describe job without the scheduler and the ingestion stack: the resources are appended
+// to the job-<id>.jsonl file of outDir and the outcome is returned rather than delivered. The jobs missing their
+// source type or description time are taken as Azure jobs described now.
+func RunLocalJob(ctx context.Context, logger *zap.Logger, vlt vault.VaultSourceConfig, job describe.DescribeJob, outDir string) JobStatusRecord {
+	if job.SourceType == "" {
+		job.SourceType = source.CloudAzure
+	}
+	if job.DescribedAt == 0 {
+		job.DescribedAt = time.Now().UnixMilli()
+	}
+
+	record := JobStatusRecord{
+		JobID:         job.JobID,
+		ResourceType:  job.ResourceType,
+		AccountID:     job.AccountID,
+		ResourcesFile: filepath.Join(outDir, fmt.Sprintf("job-%d.jsonl", job.JobID)),
+		StartedAt:     time.Now().UTC(),
+	}
+	// the file sink appends, a job run again replaces its resources
+	if err := os.Remove(record.ResourcesFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		record.FinishedAt = time.Now().UTC()
+		record.setResult(jobResult(nil, err))
+		return record
+	}
+	resourceIDs, err := Do(ctx, vlt, logger, job, SinkConfig{Type: SinkTypeFile, FilePath: record.ResourcesFile})
+	record.FinishedAt = time.Now().UTC()
+	record.ResourceCount = len(resourceIDs)
+	record.setResult(jobResult(resourceIDs, err))
+	return record
+}
+
+func (r *JobStatusRecord) setResult(result JobResult) {
+	r.Status, r.ErrorCode, r.ErrorCategory, r.Error = result.Status, result.ErrorCode, result.ErrorCategory, result.Error
+}
@@ -0,0 +1,56 @@
+package describer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LocalVault is the vault of the jobs run without the platform. The cipher text of a job is the name of its
+// credentials in the vault file, or the credentials themselves as a JSON object, e.g.
+// {"tenantId":"...","clientId":"...","clientSecret":"...","subscriptionId":"..."}.
+type LocalVault struct {
+	secrets map[string]map[string]any
+}
+
+// NewLocalVault reads the vault file at path, a JSON object mapping the credential names to the credentials.
+// Without a file only the plaintext credentials are accepted.
+func NewLocalVault(path string) (*LocalVault, error) {
+	v := &LocalVault{secrets: map[string]map[string]any{}}
+	if path == "" {
+		return v, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read vault file: %w", err)
+	}
+	if err := json.Unmarshal(content, &v.secrets); err != nil {
+		return nil, fmt.Errorf("parse vault file: %w", err)
+	}
+	return v, nil
+}
+
+// Encrypt returns the plaintext credentials, the vault file is read-only.
+func (v *LocalVault) Encrypt(_ context.Context, data map[string]any) (string, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (v *LocalVault) Decrypt(_ context.Context, cipherText string) (map[string]any, error) {
+	if secret, ok := v.secrets[cipherText]; ok {
+		return secret, nil
+	}
+	if strings.HasPrefix(strings.TrimSpace(cipherText), "{") {
+		var secret map[string]any
+		if err := json.Unmarshal([]byte(cipherText), &secret); err != nil {
+			return nil, fmt.Errorf("parse plaintext credentials: %w", err)
+		}
+		return secret, nil
+	}
+	return nil, fmt.Errorf("credentials %s not found in the vault file", cipherText)
+}
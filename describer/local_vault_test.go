@@ -0,0 +1,37 @@
+package describer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalVault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	if err := os.WriteFile(path, []byte(`{"prod":{"tenantId":"t","clientId":"c"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewLocalVault(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	plaintext, err := v.Encrypt(ctx, map[string]any{"clientId": "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for cipherText, clientID := range map[string]string{"prod": "c", ` {"clientId":"d"}`: "d", plaintext: "p"} {
+		secret, err := v.Decrypt(ctx, cipherText)
+		if err != nil {
+			t.Fatalf("%s: %v", cipherText, err)
+		}
+		if secret["clientId"] != clientID {
+			t.Errorf("%s: got client %v, want %s", cipherText, secret["clientId"], clientID)
+		}
+	}
+	if _, err := v.Decrypt(ctx, "staging"); err == nil {
+		t.Error("expected an error for credentials missing from the vault file")
+	}
+}
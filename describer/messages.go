@@ -34,3 +34,23 @@ type Resource struct {
 	// CreatedAt is when the DescribeSourceJob is created
 	CreatedAt int64 `json:"created_at"`
 }
+
+// PartialFailureReport is delivered as the error of a PARTIAL describe job, and of a FAILED one whose scopes
+// all failed.
+type PartialFailureReport struct {
+	// IngestedResourceCount is the number of resources that were sent to the sink.
+	IngestedResourceCount int `json:"ingestedResourceCount"`
+	// FailedScopes are the subscriptions, resource groups and parent resources that could not be described.
+	FailedScopes []FailedScopeReport `json:"failedScopes"`
+}
+
+type FailedScopeReport struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	// ErrorCategory is the stable category of the error, see azure/describer.ErrorCategory.
+	ErrorCategory string `json:"errorCategory"`
+	ErrorCode     string `json:"errorCode"`
+	Error         string `json:"error"`
+	StatusCode    int    `json:"statusCode,omitempty"`
+	RequestID     string `json:"requestId,omitempty"`
+}
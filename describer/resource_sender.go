@@ -2,30 +2,23 @@ package describer
 
 import (
 	"context"
-	"crypto/sha256"
-	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
-	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/opengovern/og-util/pkg/es"
 	es2 "github.com/opengovern/og-util/pkg/es"
 	"github.com/opengovern/og-util/pkg/source"
-	"google.golang.org/grpc/credentials/insecure"
-	"google.golang.org/protobuf/types/known/anypb"
-	"io"
-	"net/http"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"math/rand/v2"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/opengovern/og-util/proto/src/golang"
 	"go.uber.org/zap"
-	"golang.org/x/oauth2"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/credentials/oauth"
-	"google.golang.org/grpc/metadata"
 )
 
 const (
@@ -33,277 +26,489 @@ const (
 	MaxBufferSize   int           = 100
 	ChannelSize     int           = 1000
 	BufferEmptyRate time.Duration = 5 * time.Second
+	// MaxBatchBytes bounds the serialized size of a batch, sinks implementing BatchLimiter may lower it.
+	MaxBatchBytes int64 = 4 << 20
+
+	// CloseTimeout bounds the time spent flushing the queued resources once the describe job is done.
+	CloseTimeout time.Duration = 2 * time.Minute
+
+	MaxSendAttempts    int           = 5
+	InitialSendBackoff time.Duration = 500 * time.Millisecond
+	MaxSendBackoff     time.Duration = 30 * time.Second
+	// MaxSpillBytes bounds the size of the documents kept on disk while the sink is unavailable.
+	MaxSpillBytes int64 = 512 << 20
+
+	SpillDirEnv = "DESCRIBER_SPILL_DIR"
 )
 
 type ResourceSender struct {
-	authToken                 string
-	logger                    *zap.Logger
-	resourceChannel           chan *golang.AzureResource
-	resourceIDs               []string
-	doneChannel               chan interface{}
-	conn                      *grpc.ClientConn
-	grpcEndpoint              string
-	ingestionPipelineEndpoint string
-	jobID                     uint
-
-	client     golang.EsSinkServiceClient
-	httpClient *http.Client
-
-	sendBuffer    []*golang.AzureResource
-	useOpenSearch bool
+	logger          *zap.Logger
+	resourceChannel chan *golang.AzureResource
+	resourceIDs     []string
+	closeChannel    chan context.Context
+	doneChannel     chan struct{}
+	closeOnce       sync.Once
+	closeErr        error
+	jobID           uint
+
+	// ctx is the context of the describe job, the batches are sent with it until Close is called.
+	ctx context.Context
+
+	sink          Sink
+	maxBatchBytes int64
+
+	sendBuffer      resourceBatch
+	sendBufferBytes int64
+
+	// spill holds the batches that failed all their attempts, it is created on the first failure.
+	spill *spillQueue
+
+	stats senderStats
 }
 
-func NewResourceSender(grpcEndpoint, ingestionPipelineEndpoint string, describeToken string, jobID uint, useOpenSearch bool, logger *zap.Logger) (*ResourceSender, error) {
-	rs := ResourceSender{
-		authToken:                 describeToken,
-		logger:                    logger,
-		resourceChannel:           make(chan *golang.AzureResource, ChannelSize),
-		resourceIDs:               nil,
-		doneChannel:               make(chan interface{}),
-		conn:                      nil,
-		grpcEndpoint:              grpcEndpoint,
-		ingestionPipelineEndpoint: ingestionPipelineEndpoint,
-		jobID:                     jobID,
-		useOpenSearch:             useOpenSearch,
-
-		httpClient: &http.Client{Timeout: 10 * time.Second},
-	}
-	if err := rs.Connect(); err != nil {
-		return nil, err
-	}
+// BatchLimiter is implemented by the sinks bounding the size of their requests.
+type BatchLimiter interface {
+	// MaxBatchBytes is the maximum serialized size of the documents of an Ingest call.
+	MaxBatchBytes() int64
+}
 
-	go rs.ResourceHandler()
-	return &rs, nil
+// SenderStats are the counters and queue depths of a ResourceSender.
+type SenderStats struct {
+	// QueueDepth is the number of resources waiting to be batched, MaxQueueDepth the highest it reached.
+	QueueDepth    int64
+	MaxQueueDepth int64
+	// BufferedResources and BufferedBytes are the size of the batch being filled.
+	BufferedResources int64
+	BufferedBytes     int64
+	SpilledBatches    int64
+
+	Batches               int64
+	SplitBatches          int64
+	AcknowledgedResources int64
+	// UndeliveredResources are the resources dropped without being acknowledged by the sink.
+	UndeliveredResources int64
 }
 
-func (s *ResourceSender) Connect() error {
-	var opts []grpc.DialOption
-	if s.authToken != "" {
-		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})))
-		opts = append(opts, grpc.WithPerRPCCredentials(oauth.TokenSource{
-			TokenSource: oauth2.StaticTokenSource(&oauth2.Token{
-				AccessToken: s.authToken,
-			}),
-		}))
-	} else {
-		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	}
+type senderStats struct {
+	maxQueueDepth         atomic.Int64
+	bufferedResources     atomic.Int64
+	bufferedBytes         atomic.Int64
+	spilledBatches        atomic.Int64
+	batches               atomic.Int64
+	splitBatches          atomic.Int64
+	acknowledgedResources atomic.Int64
+	undeliveredResources  atomic.Int64
+}
 
-	conn, err := grpc.NewClient(
-		s.grpcEndpoint,
-		opts...,
-	)
-	if err != nil {
-		return err
+// NewResourceSender starts sending the resources queued with Send to the sink. The caller must Close it,
+// including on the error paths, so that the queued resources are delivered and the sink is closed.
+func NewResourceSender(ctx context.Context, sink Sink, jobID uint, logger *zap.Logger) *ResourceSender {
+	rs := ResourceSender{
+		logger:          logger,
+		resourceChannel: make(chan *golang.AzureResource, ChannelSize),
+		resourceIDs:     nil,
+		closeChannel:    make(chan context.Context),
+		doneChannel:     make(chan struct{}),
+		ctx:             ctx,
+		jobID:           jobID,
+
+		sink:          sink,
+		maxBatchBytes: MaxBatchBytes,
+	}
+	if limiter, ok := sink.(BatchLimiter); ok && limiter.MaxBatchBytes() < rs.maxBatchBytes {
+		rs.maxBatchBytes = limiter.MaxBatchBytes()
 	}
-	s.conn = conn
 
-	client := golang.NewEsSinkServiceClient(conn)
-	s.client = client
-	return nil
+	go rs.ResourceHandler()
+	return &rs
 }
 
 func (s *ResourceSender) ResourceHandler() {
+	defer close(s.doneChannel)
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("resource sender panicked", zap.Any("panic", r), zap.Stack("stackTrace"))
+			s.drop(len(s.sendBuffer.Resources) + len(s.resourceChannel))
+			s.sendBuffer = resourceBatch{}
+		}
+	}()
+
 	t := time.NewTicker(BufferEmptyRate)
 	defer t.Stop()
 
 	for {
 		select {
 		case resource := <-s.resourceChannel:
-			if resource == nil {
-				s.flushBuffer(true)
-				s.doneChannel <- struct{}{}
-				return
+			s.bufferResource(s.ctx, resource)
+		case <-t.C:
+			if depth := len(s.resourceChannel); depth > ChannelSize/2 {
+				s.logger.Warn("resource queue is filling up, the sink is slower than the describers",
+					zap.Int("queueDepth", depth), zap.Int("queueSize", ChannelSize))
 			}
-
-			s.resourceIDs = append(s.resourceIDs, resource.UniqueId)
-			s.sendBuffer = append(s.sendBuffer, resource)
-
-			if len(s.sendBuffer) > MaxBufferSize {
-				s.flushBuffer(true)
+			s.flushBuffer(s.ctx, false)
+		case ctx := <-s.closeChannel:
+			for len(s.resourceChannel) > 0 {
+				s.bufferResource(ctx, <-s.resourceChannel)
 			}
-		case <-t.C:
-			s.flushBuffer(false)
+			s.flushBuffer(ctx, true)
+			s.replaySpilled(ctx)
+			s.dropSpilled()
+			return
 		}
 	}
 }
 
-func (s *ResourceSender) sendToBackend(resourcesToSend []es.Doc) {
-	grpcCtx := metadata.NewOutgoingContext(context.Background(), metadata.New(map[string]string{
-		"resource-job-id": fmt.Sprintf("%d", s.jobID),
-	}))
+func (s *ResourceSender) sendToBackend(ctx context.Context, docs []json.RawMessage) error {
+	return s.sink.Ingest(ctx, docs)
+}
 
-	docs := make([]*anypb.Any, 0, len(resourcesToSend))
-	for _, resource := range resourcesToSend {
-		docBytes, err := json.Marshal(resource)
-		if err != nil {
-			s.logger.Error("failed to marshal resource", zap.Error(err))
-			continue
+// sendWithRetry sends the batch until the sink acknowledges it, backing off exponentially between
+// the attempts. Only the resources the sink did not acknowledge are sent again. It gives up after
+// MaxSendAttempts or on an error that is not worth retrying and returns the unacknowledged resources.
+func (s *ResourceSender) sendWithRetry(ctx context.Context, batch resourceBatch) (resourceBatch, error) {
+	backoff := InitialSendBackoff
+	for attempt := 1; ; attempt++ {
+		err := s.sendToBackend(ctx, batch.docs())
+		if err == nil {
+			s.ack(batch)
+			return resourceBatch{}, nil
 		}
-		docs = append(docs, &anypb.Any{Value: docBytes})
-	}
 
-	_, err := s.client.Ingest(grpcCtx, &golang.IngestRequest{Docs: docs})
-	if err != nil {
-		s.logger.Error("failed to send resource", zap.Error(err))
-		if errors.Is(err, io.EOF) {
-			err = s.Connect()
-			if err != nil {
-				s.logger.Error("failed to reconnect", zap.Error(err))
-			}
+		if errors.Is(err, ErrBatchTooLarge) {
+			return s.splitAndSend(ctx, batch, err)
 		}
-		return
+
+		var partial PartialSinkError
+		if errors.As(err, &partial) {
+			var ingested resourceBatch
+			ingested, batch = batch.split(partial.FailedDocs)
+			s.ack(ingested)
+		}
+		if attempt >= MaxSendAttempts || !isRetryableSendError(err) {
+			return batch, err
+		}
+
+		s.logger.Warn("failed to send resources, retrying", zap.Error(err), zap.Int("attempt", attempt), zap.Duration("backoff", backoff))
+
+		select {
+		case <-time.After(backoff/2 + time.Duration(rand.Int64N(int64(backoff/2)+1))):
+		case <-ctx.Done():
+			return batch, ctx.Err()
+		}
+		backoff *= 2
+		if backoff > MaxSendBackoff {
+			backoff = MaxSendBackoff
+		}
+	}
+}
+
+// splitAndSend sends the two halves of a batch the sink rejected as too large. A single resource
+// too large for the sink is given up on.
+func (s *ResourceSender) splitAndSend(ctx context.Context, batch resourceBatch, err error) (resourceBatch, error) {
+	if len(batch.Resources) < 2 {
+		return batch, PermanentSinkError{Err: err}
+	}
+	s.stats.splitBatches.Add(1)
+	s.logger.Warn("batch is too large for the sink, splitting it", zap.Int("resources", len(batch.Resources)), zap.Int64("bytes", batch.size()))
+
+	half := len(batch.Resources) / 2
+	failedFirst, errFirst := s.sendWithRetry(ctx, resourceBatch{Resources: batch.Resources[:half]})
+	failedSecond, errSecond := s.sendWithRetry(ctx, resourceBatch{Resources: batch.Resources[half:]})
+
+	failed := resourceBatch{Resources: append(failedFirst.Resources, failedSecond.Resources...)}
+	// report the retryable error if any, so that the resources are spilled rather than dropped
+	switch {
+	case errFirst == nil:
+		return failed, errSecond
+	case errSecond == nil || isRetryableSendError(errFirst):
+		return failed, errFirst
+	default:
+		return failed, errSecond
 	}
 }
 
-func (s *ResourceSender) sendToOpenSearchIngestPipeline(resourcesToSend []es.Doc) {
+func (s *ResourceSender) ack(batch resourceBatch) {
+	s.resourceIDs = append(s.resourceIDs, batch.ids()...)
+	s.stats.acknowledgedResources.Add(int64(len(batch.Resources)))
+}
+
+func (s *ResourceSender) drop(n int) {
+	s.stats.undeliveredResources.Add(int64(n))
+}
+
+func isRetryableSendError(err error) bool {
+	if isPermanentSinkError(err) {
+		return false
+	}
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.ResourceExhausted, codes.Internal, codes.Unknown:
+			return true
+		}
+		return false
+	}
+	return true
+}
 
-	if len(resourcesToSend) == 0 {
+// deliver sends the batch and records its resources as described once the sink acknowledged them.
+// The resources failing all their attempts are spilled to disk and retried after the next successful send.
+func (s *ResourceSender) deliver(ctx context.Context, batch resourceBatch) {
+	if len(batch.Resources) == 0 {
 		return
 	}
 
-	jsonResourcesToSend, err := json.Marshal(resourcesToSend)
+	s.stats.batches.Add(1)
+	failed, err := s.sendWithRetry(ctx, batch)
 	if err != nil {
-		s.logger.Error("failed to marshal resources", zap.Error(err))
+		if !isRetryableSendError(err) {
+			s.logger.Error("sink rejected resources, dropping them", zap.Error(err), zap.Int("resources", len(failed.Resources)))
+			s.drop(len(failed.Resources))
+			return
+		}
+		s.logger.Error("failed to send resources, spilling them to disk", zap.Error(err), zap.Int("resources", len(failed.Resources)))
+		s.spillBatch(failed)
 		return
 	}
 
-	req, err := http.NewRequest(
-		http.MethodPost,
-		s.ingestionPipelineEndpoint,
-		strings.NewReader(string(jsonResourcesToSend)),
-	)
-	req.Header.Add("Content-Type", "application/json")
+	s.replaySpilled(ctx)
+}
 
-	cfg, err := config.LoadDefaultConfig(context.TODO())
-	if err != nil {
-		s.logger.Error("failed to load configuration", zap.Error(err))
-		return
+func (s *ResourceSender) spillBatch(batch resourceBatch) {
+	if s.spill == nil {
+		spill, err := newSpillQueue(os.Getenv(SpillDirEnv), MaxSpillBytes)
+		if err != nil {
+			s.logger.Error("failed to create spill queue, dropping resources", zap.Error(err), zap.Int("resources", len(batch.Resources)))
+			s.drop(len(batch.Resources))
+			return
+		}
+		s.spill = spill
 	}
-	creds, err := cfg.Credentials.Retrieve(context.Background())
-	if err != nil {
-		s.logger.Error("failed to retrieve credentials", zap.Error(err))
-		return
+
+	if err := s.spill.Push(batch); err != nil {
+		s.logger.Error("failed to spill resources, dropping them", zap.Error(err), zap.Int("resources", len(batch.Resources)))
+		s.drop(len(batch.Resources))
 	}
+	s.stats.spilledBatches.Store(int64(s.spill.Len()))
+}
 
-	signer := v4.NewSigner()
-	err = signer.SignHTTP(context.TODO(), creds, req,
-		fmt.Sprintf("%x", sha256.Sum256(jsonResourcesToSend)),
-		"osis", "us-east-2", time.Now())
-	if err != nil {
-		s.logger.Error("failed to sign request", zap.Error(err))
-		return
+// replaySpilled sends the spilled batches in order, it stops at the first one the sink does not acknowledge.
+func (s *ResourceSender) replaySpilled(ctx context.Context) {
+	for s.spill != nil && s.spill.Len() > 0 {
+		batch, err := s.spill.Peek()
+		if err != nil {
+			s.logger.Error("failed to read spilled resources, dropping them", zap.Error(err))
+			s.spill.Pop()
+			continue
+		}
+
+		failed, err := s.sendWithRetry(ctx, batch)
+		if err != nil && isRetryableSendError(err) {
+			s.logger.Warn("failed to send spilled resources", zap.Error(err), zap.Int("spilledBatches", s.spill.Len()))
+			if len(failed.Resources) < len(batch.Resources) {
+				// keep only the resources still to deliver
+				s.spill.Pop()
+				s.spillBatch(failed)
+			}
+			return
+		}
+		if err != nil {
+			s.logger.Error("sink rejected spilled resources, dropping them", zap.Error(err), zap.Int("resources", len(failed.Resources)))
+			s.drop(len(failed.Resources))
+		}
+		s.spill.Pop()
+		s.stats.spilledBatches.Store(int64(s.spill.Len()))
 	}
+}
 
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		s.logger.Error("failed to send request", zap.Error(err))
+// dropSpilled gives up on the batches still spilled, their resources are not reported as described.
+func (s *ResourceSender) dropSpilled() {
+	if s.spill == nil {
 		return
 	}
-	defer resp.Body.Close()
-	// check status
-	if resp.StatusCode != http.StatusOK {
-		bodyStr := ""
-		if resp.Body != nil {
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				s.logger.Warn("failed to read response body", zap.Error(err))
-			} else {
-				bodyStr = string(bodyBytes)
-			}
+
+	for s.spill.Len() > 0 {
+		if batch, err := s.spill.Peek(); err == nil {
+			s.drop(len(batch.Resources))
 		}
-		s.logger.Error("failed to send resources to OpenSearch",
-			zap.Int("statusCode", resp.StatusCode),
-			zap.String("body", bodyStr),
-		)
-		return
+		s.spill.Pop()
+	}
+	if err := s.spill.Close(); err != nil {
+		s.logger.Warn("failed to remove spill directory", zap.Error(err))
 	}
+	s.spill = nil
+	s.stats.spilledBatches.Store(0)
 }
 
-func (s *ResourceSender) flushBuffer(force bool) {
-	if len(s.sendBuffer) == 0 {
+// bufferResource adds the documents of the resource to the batch being filled. The batch is sent once it holds
+// MaxBufferSize resources, or before it gets larger than the maximum batch size of the sink.
+func (s *ResourceSender) bufferResource(ctx context.Context, resource *golang.AzureResource) {
+	r, ok := s.toBatchResource(resource)
+	if !ok {
 		return
 	}
 
-	if !force && len(s.sendBuffer) < MinBufferSize {
-		return
+	size := resourceBatch{Resources: []batchResource{r}}.size()
+	if len(s.sendBuffer.Resources) > 0 && s.sendBufferBytes+size > s.maxBatchBytes {
+		s.flushBuffer(ctx, true)
+	}
+	s.sendBuffer.Resources = append(s.sendBuffer.Resources, r)
+	s.sendBufferBytes += size
+	s.stats.bufferedResources.Store(int64(len(s.sendBuffer.Resources)))
+	s.stats.bufferedBytes.Store(s.sendBufferBytes)
+
+	if len(s.sendBuffer.Resources) >= MaxBufferSize || s.sendBufferBytes >= s.maxBatchBytes {
+		s.flushBuffer(ctx, true)
 	}
+}
 
-	resourcesToSend := make([]es2.Doc, 0, 2*len(s.sendBuffer))
-	for _, resource := range s.sendBuffer {
-		var description any
-		err := json.Unmarshal([]byte(resource.DescriptionJson), &description)
+func (s *ResourceSender) toBatchResource(resource *golang.AzureResource) (batchResource, bool) {
+	// the description is embedded as is, marshalling the document validates it
+	description := json.RawMessage(resource.DescriptionJson)
+
+	tags := make([]es.Tag, 0, len(resource.Tags))
+	for k, v := range resource.Tags {
+		tags = append(tags, es.Tag{
+			// tags should be case-insensitive
+			Key:   strings.ToLower(k),
+			Value: strings.ToLower(v),
+		})
+	}
+
+	kafkaResource := es.Resource{
+		ID:            resource.UniqueId,
+		ARN:           "",
+		Description:   description,
+		SourceType:    source.CloudAzure,
+		ResourceType:  strings.ToLower(resource.Job.ResourceType),
+		ResourceJobID: uint(resource.Job.JobId),
+		SourceID:      resource.Job.SourceId,
+		SourceJobID:   uint(resource.Job.ParentJobId),
+		Metadata:      resource.Metadata,
+		Name:          resource.Name,
+		ResourceGroup: resource.ResourceGroup,
+		Location:      resource.Location,
+		ScheduleJobID: uint(resource.Job.ScheduleJobId),
+		CreatedAt:     resource.Job.DescribedAt,
+		CanonicalTags: tags,
+	}
+	keys, idx := kafkaResource.KeysAndIndex()
+	kafkaResource.EsID = es2.HashOf(keys...)
+	kafkaResource.EsIndex = idx
+
+	lookupResource := es.LookupResource{
+		ResourceID:    resource.UniqueId,
+		Name:          resource.Name,
+		SourceType:    source.CloudAzure,
+		ResourceType:  strings.ToLower(resource.Job.ResourceType),
+		ResourceGroup: resource.ResourceGroup,
+		Location:      resource.Location,
+		SourceID:      resource.Job.SourceId,
+		ResourceJobID: uint(resource.Job.JobId),
+		SourceJobID:   uint(resource.Job.ParentJobId),
+		ScheduleJobID: uint(resource.Job.ScheduleJobId),
+		CreatedAt:     resource.Job.DescribedAt,
+		Tags:          tags,
+	}
+	lookupKeys, lookupIdx := lookupResource.KeysAndIndex()
+	lookupResource.EsID = es2.HashOf(lookupKeys...)
+	lookupResource.EsIndex = lookupIdx
+
+	var docs []json.RawMessage
+	for _, doc := range []es2.Doc{kafkaResource, lookupResource} {
+		docBytes, err := json.Marshal(doc)
 		if err != nil {
-			s.logger.Error("failed to parse resource description json", zap.Error(err), zap.Uint32("jobID", resource.Job.JobId), zap.String("resourceID", resource.Id))
-			continue
+			s.logger.Error("failed to marshal resource", zap.Error(err), zap.String("resourceID", resource.Id))
+			return batchResource{}, false
 		}
+		docs = append(docs, docBytes)
+	}
 
-		tags := make([]es.Tag, 0, len(resource.Tags))
-		for k, v := range resource.Tags {
-			tags = append(tags, es.Tag{
-				// tags should be case-insensitive
-				Key:   strings.ToLower(k),
-				Value: strings.ToLower(v),
-			})
-		}
+	return batchResource{ID: resource.UniqueId, Docs: docs}, true
+}
 
-		kafkaResource := es.Resource{
-			ID:            resource.UniqueId,
-			ARN:           "",
-			Description:   description,
-			SourceType:    source.CloudAzure,
-			ResourceType:  strings.ToLower(resource.Job.ResourceType),
-			ResourceJobID: uint(resource.Job.JobId),
-			SourceID:      resource.Job.SourceId,
-			SourceJobID:   uint(resource.Job.ParentJobId),
-			Metadata:      resource.Metadata,
-			Name:          resource.Name,
-			ResourceGroup: resource.ResourceGroup,
-			Location:      resource.Location,
-			ScheduleJobID: uint(resource.Job.ScheduleJobId),
-			CreatedAt:     resource.Job.DescribedAt,
-			CanonicalTags: tags,
-		}
-		keys, idx := kafkaResource.KeysAndIndex()
-		kafkaResource.EsID = es2.HashOf(keys...)
-		kafkaResource.EsIndex = idx
-
-		lookupResource := es.LookupResource{
-			ResourceID:    resource.UniqueId,
-			Name:          resource.Name,
-			SourceType:    source.CloudAzure,
-			ResourceType:  strings.ToLower(resource.Job.ResourceType),
-			ResourceGroup: resource.ResourceGroup,
-			Location:      resource.Location,
-			SourceID:      resource.Job.SourceId,
-			ResourceJobID: uint(resource.Job.JobId),
-			SourceJobID:   uint(resource.Job.ParentJobId),
-			ScheduleJobID: uint(resource.Job.ScheduleJobId),
-			CreatedAt:     resource.Job.DescribedAt,
-			Tags:          tags,
-		}
-		lookupKeys, lookupIdx := lookupResource.KeysAndIndex()
-		lookupResource.EsID = es2.HashOf(lookupKeys...)
-		lookupResource.EsIndex = lookupIdx
+func (s *ResourceSender) flushBuffer(ctx context.Context, force bool) {
+	if len(s.sendBuffer.Resources) == 0 {
+		return
+	}
 
-		resourcesToSend = append(resourcesToSend, kafkaResource)
-		resourcesToSend = append(resourcesToSend, lookupResource)
+	if !force && len(s.sendBuffer.Resources) < MinBufferSize {
+		return
 	}
 
-	s.sendToBackend(resourcesToSend)
-	s.sendBuffer = nil
+	batch := s.sendBuffer
+	s.sendBuffer = resourceBatch{}
+	s.sendBufferBytes = 0
+	s.stats.bufferedResources.Store(0)
+	s.stats.bufferedBytes.Store(0)
+	s.deliver(ctx, batch)
 }
 
-func (s *ResourceSender) Finish() {
-	s.resourceChannel <- nil
-	_ = <-s.doneChannel
-	s.conn.Close()
+// Close flushes the queued resources and closes the sink, the resources still undelivered once ctx is done are
+// given up on and left out of GetResourceIDs. It returns once the sender stopped, Close can be called several times.
+func (s *ResourceSender) Close(ctx context.Context) error {
+	s.closeOnce.Do(func() {
+		select {
+		case s.closeChannel <- ctx:
+		case <-s.doneChannel:
+		}
+		// the pending sends use ctx, the handler stops soon after it is done
+		<-s.doneChannel
+
+		if undelivered := s.UndeliveredCount(); undelivered > 0 {
+			s.logger.Error("resources were not delivered", zap.Int("count", undelivered))
+		}
+		if err := ctx.Err(); err != nil {
+			s.closeErr = fmt.Errorf("flush resources: %w", err)
+		}
+		if err := s.sink.Close(); err != nil {
+			s.logger.Warn("failed to close sink", zap.Error(err))
+		}
+	})
+	return s.closeErr
 }
 
+// GetResourceIDs returns the IDs of the resources acknowledged by the sink.
 func (s *ResourceSender) GetResourceIDs() []string {
 	return s.resourceIDs
 }
 
-func (s *ResourceSender) Send(resource *golang.AzureResource) {
-	s.resourceChannel <- resource
+// UndeliveredCount returns the number of resources sent to the ResourceSender that the sink never acknowledged.
+func (s *ResourceSender) UndeliveredCount() int {
+	return int(s.stats.undeliveredResources.Load())
+}
+
+func (s *ResourceSender) Stats() SenderStats {
+	return SenderStats{
+		QueueDepth:            int64(len(s.resourceChannel)),
+		MaxQueueDepth:         s.stats.maxQueueDepth.Load(),
+		BufferedResources:     s.stats.bufferedResources.Load(),
+		BufferedBytes:         s.stats.bufferedBytes.Load(),
+		SpilledBatches:        s.stats.spilledBatches.Load(),
+		Batches:               s.stats.batches.Load(),
+		SplitBatches:          s.stats.splitBatches.Load(),
+		AcknowledgedResources: s.stats.acknowledgedResources.Load(),
+		UndeliveredResources:  s.stats.undeliveredResources.Load(),
+	}
+}
+
+// Send queues the resource, it blocks while the queue is full. It fails once the sender is closed.
+func (s *ResourceSender) Send(resource *golang.AzureResource) error {
+	select {
+	case <-s.doneChannel:
+		return errors.New("resource sender is closed")
+	default:
+	}
+	select {
+	case s.resourceChannel <- resource:
+	case <-s.doneChannel:
+		return errors.New("resource sender is closed")
+	}
+	depth := int64(len(s.resourceChannel))
+	for {
+		maxDepth := s.stats.maxQueueDepth.Load()
+		if depth <= maxDepth || s.stats.maxQueueDepth.CompareAndSwap(maxDepth, depth) {
+			break
+		}
+	}
+	return nil
 }
@@ -0,0 +1,228 @@
+package describer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/opengovern/og-util/pkg/describe"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+// Sink is where the ResourceSender delivers the described resources.
+type Sink interface {
+	// Ingest delivers the documents, it returns once the sink acknowledged all of them.
+	// The ResourceSender retries the errors unless they are wrapped with PermanentSinkError.
+	Ingest(ctx context.Context, docs []json.RawMessage) error
+	Close() error
+}
+
+type SinkType string
+
+const (
+	SinkTypeGRPC              SinkType = "grpc"
+	SinkTypeIngestionPipeline SinkType = "opensearch-ingestion"
+	SinkTypeOpenSearch        SinkType = "opensearch"
+	SinkTypeKafka             SinkType = "kafka"
+	SinkTypeNATS              SinkType = "nats"
+	SinkTypeFile              SinkType = "file"
+)
+
+const (
+	SinkTypeEnv                  = "DESCRIBER_SINK"
+	GRPCMaxMessageBytesEnv       = "DESCRIBER_GRPC_MAX_MESSAGE_BYTES"
+	IngestionPipelineEndpointEnv = "DESCRIBER_INGESTION_PIPELINE_ENDPOINT"
+	IngestionPipelineRegionEnv   = "DESCRIBER_INGESTION_PIPELINE_REGION"
+	IngestionPipelineServiceEnv  = "DESCRIBER_INGESTION_PIPELINE_SERVICE"
+	OpenSearchAddressesEnv       = "DESCRIBER_OPENSEARCH_ADDRESSES"
+	OpenSearchUsernameEnv        = "DESCRIBER_OPENSEARCH_USERNAME"
+	OpenSearchPasswordEnv        = "DESCRIBER_OPENSEARCH_PASSWORD"
+	OpenSearchCAFileEnv          = "DESCRIBER_OPENSEARCH_CA_FILE"
+	OpenSearchCertFileEnv        = "DESCRIBER_OPENSEARCH_CERT_FILE"
+	OpenSearchKeyFileEnv         = "DESCRIBER_OPENSEARCH_KEY_FILE"
+	KafkaBrokersEnv              = "DESCRIBER_KAFKA_BROKERS"
+	KafkaTopicEnv                = "DESCRIBER_KAFKA_TOPIC"
+	NATSURLEnv                   = "DESCRIBER_NATS_URL"
+	NATSSubjectEnv               = "DESCRIBER_NATS_SUBJECT"
+	FileSinkPathEnv              = "DESCRIBER_SINK_FILE"
+)
+
+// SinkConfig selects and configures the Sink of a describe job.
+type SinkConfig struct {
+	Type SinkType
+
+	// GRPCEndpoint is the address of the EsSinkService, the tokens of AuthTokens authenticate the calls to it.
+	GRPCEndpoint string
+	AuthTokens   oauth2.TokenSource
+	GRPCTLS      GRPCTLSConfig
+	// GRPCMaxMessageBytes is the maximum size of an ingest request accepted by the EsSinkService,
+	// DefaultGRPCMaxMessageBytes if 0.
+	GRPCMaxMessageBytes int
+
+	// IngestionPipelineEndpoint is the URL of the OpenSearch Ingestion pipeline. The region and the service
+	// signing the requests to AWS are derived from it unless they are set.
+	IngestionPipelineEndpoint string
+	IngestionPipelineRegion   string
+	IngestionPipelineService  string
+
+	// OpenSearchAddresses are the base URLs of the cluster the documents are bulk indexed into.
+	OpenSearchAddresses []string
+	// OpenSearchUsername and OpenSearchPassword authenticate the OpenSearch and the non-AWS ingestion
+	// pipeline requests with basic auth, OpenSearchTLS secures their connections.
+	OpenSearchUsername string
+	OpenSearchPassword string
+	OpenSearchTLS      HTTPSinkTLS
+
+	KafkaBrokers []string
+	KafkaTopic   string
+
+	NATSURL     string
+	NATSSubject string
+
+	// FilePath is the file the documents are appended to as JSON lines.
+	FilePath string
+
+	// Compression encodes the ingest requests of the gRPC, OpenSearch, ingestion pipeline and Kafka sinks.
+	Compression Compression
+}
+
+// SinkConfigFromInput builds the sink configuration of a describe job. The job input selects the gRPC sink
+// of the ingestion stack, or its OpenSearch Ingestion pipeline if UseOpenSearch is set. The DESCRIBER_*
+// environment variables override it for self-hosted deployments.
+func SinkConfigFromInput(input describe.DescribeWorkerInput, authTokens oauth2.TokenSource) SinkConfig {
+	cfg := SinkConfig{
+		Type:         SinkTypeGRPC,
+		GRPCEndpoint: input.DeliverEndpoint,
+		AuthTokens:   authTokens,
+		GRPCTLS:      GRPCTLSConfigFromEnv(),
+
+		IngestionPipelineEndpoint: input.IngestionPipelineEndpoint,
+		IngestionPipelineRegion:   os.Getenv(IngestionPipelineRegionEnv),
+		IngestionPipelineService:  os.Getenv(IngestionPipelineServiceEnv),
+		OpenSearchAddresses:       splitEnvList(os.Getenv(OpenSearchAddressesEnv)),
+		OpenSearchUsername:        os.Getenv(OpenSearchUsernameEnv),
+		OpenSearchPassword:        os.Getenv(OpenSearchPasswordEnv),
+		OpenSearchTLS: HTTPSinkTLS{
+			CAFile:   os.Getenv(OpenSearchCAFileEnv),
+			CertFile: os.Getenv(OpenSearchCertFileEnv),
+			KeyFile:  os.Getenv(OpenSearchKeyFileEnv),
+		},
+		KafkaBrokers: splitEnvList(os.Getenv(KafkaBrokersEnv)),
+		KafkaTopic:   os.Getenv(KafkaTopicEnv),
+		NATSURL:      os.Getenv(NATSURLEnv),
+		NATSSubject:  os.Getenv(NATSSubjectEnv),
+		FilePath:     os.Getenv(FileSinkPathEnv),
+		Compression:  Compression(strings.ToLower(os.Getenv(SinkCompressionEnv))),
+	}
+	if maxBytes, err := strconv.Atoi(os.Getenv(GRPCMaxMessageBytesEnv)); err == nil {
+		cfg.GRPCMaxMessageBytes = maxBytes
+	}
+	if input.UseOpenSearch {
+		cfg.Type = SinkTypeIngestionPipeline
+	}
+	if endpoint := os.Getenv(IngestionPipelineEndpointEnv); endpoint != "" {
+		cfg.IngestionPipelineEndpoint = endpoint
+	}
+	if sinkType := os.Getenv(SinkTypeEnv); sinkType != "" {
+		cfg.Type = SinkType(strings.ToLower(sinkType))
+	}
+	return cfg
+}
+
+func NewSink(ctx context.Context, cfg SinkConfig, jobID uint, logger *zap.Logger) (Sink, error) {
+	compression, err := ParseCompression(string(cfg.Compression))
+	if err != nil {
+		return nil, err
+	}
+	cfg.Compression = compression
+	if compression != CompressionNone && (cfg.Type == SinkTypeNATS || cfg.Type == SinkTypeFile) {
+		return nil, fmt.Errorf("the %s sink does not support compression", cfg.Type)
+	}
+
+	switch cfg.Type {
+	case SinkTypeGRPC, "":
+		return newGRPCSink(cfg.GRPCEndpoint, cfg.AuthTokens, cfg.GRPCTLS, cfg.GRPCMaxMessageBytes, cfg.Compression, jobID)
+	case SinkTypeIngestionPipeline:
+		return newIngestionPipelineSink(ctx, cfg)
+	case SinkTypeOpenSearch:
+		return newOpenSearchSink(cfg)
+	case SinkTypeKafka:
+		return newKafkaSink(cfg.KafkaBrokers, cfg.KafkaTopic, cfg.Compression)
+	case SinkTypeNATS:
+		return newNATSSink(cfg.NATSURL, cfg.NATSSubject, logger)
+	case SinkTypeFile:
+		return newFileSink(cfg.FilePath)
+	default:
+		return nil, fmt.Errorf("unsupported sink type %s", cfg.Type)
+	}
+}
+
+// ErrBatchTooLarge is wrapped by the errors of the sinks rejecting a request for its size,
+// the ResourceSender splits the batch and sends the halves.
+var ErrBatchTooLarge = errors.New("batch is too large")
+
+// PermanentSinkError marks a sink error that retrying the same documents cannot fix.
+type PermanentSinkError struct {
+	Err error
+}
+
+func (e PermanentSinkError) Error() string {
+	return e.Err.Error()
+}
+
+func (e PermanentSinkError) Unwrap() error {
+	return e.Err
+}
+
+// PartialSinkError is returned by a sink that ingested only part of the documents, FailedDocs are the
+// indexes of the others. The ResourceSender acknowledges the resources of the ingested documents and
+// retries the failed ones unless Err is a PermanentSinkError.
+type PartialSinkError struct {
+	FailedDocs []int
+	Err        error
+}
+
+func (e PartialSinkError) Error() string {
+	return fmt.Sprintf("%d documents failed: %v", len(e.FailedDocs), e.Err)
+}
+
+func (e PartialSinkError) Unwrap() error {
+	return e.Err
+}
+
+func isPermanentSinkError(err error) bool {
+	var permanent PermanentSinkError
+	return errors.As(err, &permanent)
+}
+
+// docMetadata is the part of an es.Doc the sinks need to route it.
+type docMetadata struct {
+	EsID    string `json:"es_id"`
+	EsIndex string `json:"es_index"`
+}
+
+func parseDocMetadata(doc json.RawMessage) (docMetadata, error) {
+	var m docMetadata
+	if err := json.Unmarshal(doc, &m); err != nil {
+		return m, PermanentSinkError{Err: fmt.Errorf("parse document: %w", err)}
+	}
+	if m.EsIndex == "" {
+		return m, PermanentSinkError{Err: errors.New("document has no index")}
+	}
+	return m, nil
+}
+
+func splitEnvList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
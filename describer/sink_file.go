@@ -0,0 +1,40 @@
+package describer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// fileSink appends the documents to a local file as JSON lines.
+type fileSink struct {
+	file *os.File
+}
+
+func newFileSink(path string) (*fileSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%s is required by the %s sink", FileSinkPathEnv, SinkTypeFile)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSink{file: file}, nil
+}
+
+func (s *fileSink) Ingest(_ context.Context, docs []json.RawMessage) error {
+	var content []byte
+	for _, doc := range docs {
+		content = append(content, doc...)
+		content = append(content, '\n')
+	}
+	if _, err := s.file.Write(content); err != nil {
+		return PermanentSinkError{Err: err}
+	}
+	return s.file.Sync()
+}
+
+func (s *fileSink) Close() error {
+	return s.file.Close()
+}
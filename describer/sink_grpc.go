@@ -0,0 +1,153 @@
+package describer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/opengovern/og-util/proto/src/golang"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/oauth"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// DefaultGRPCMaxMessageBytes is the default maximum message size of gRPC servers.
+const DefaultGRPCMaxMessageBytes = 4 << 20
+
+// grpcSink delivers the documents to the EsSinkService of the ingestion stack.
+type grpcSink struct {
+	endpoint   string
+	authTokens oauth2.TokenSource
+	tls        GRPCTLSConfig
+	jobID      uint
+
+	maxMessageBytes int
+	compression     Compression
+
+	conn   *grpc.ClientConn
+	client golang.EsSinkServiceClient
+}
+
+func newGRPCSink(endpoint string, authTokens oauth2.TokenSource, tlsConfig GRPCTLSConfig, maxMessageBytes int, compression Compression, jobID uint) (*grpcSink, error) {
+	if maxMessageBytes <= 0 {
+		maxMessageBytes = DefaultGRPCMaxMessageBytes
+	}
+	s := &grpcSink{
+		endpoint:        endpoint,
+		authTokens:      authTokens,
+		tls:             tlsConfig,
+		jobID:           jobID,
+		maxMessageBytes: maxMessageBytes,
+		compression:     compression,
+	}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *grpcSink) connect() error {
+	creds, err := s.tls.TransportCredentials(s.authTokens != nil)
+	if err != nil {
+		return err
+	}
+	callOpts := []grpc.CallOption{grpc.MaxCallSendMsgSize(s.maxMessageBytes)}
+	if s.compression != CompressionNone {
+		callOpts = append(callOpts, grpc.UseCompressor(string(s.compression)))
+	}
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(callOpts...),
+	}
+	if s.authTokens != nil {
+		opts = append(opts, grpc.WithPerRPCCredentials(oauth.TokenSource{TokenSource: s.authTokens}))
+	}
+
+	conn, err := grpc.NewClient(
+		s.endpoint,
+		opts...,
+	)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	s.client = golang.NewEsSinkServiceClient(conn)
+	return nil
+}
+
+func (s *grpcSink) Ingest(ctx context.Context, docs []json.RawMessage) error {
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+
+	grpcCtx := metadata.NewOutgoingContext(ctx, metadata.New(map[string]string{
+		"resource-job-id": fmt.Sprintf("%d", s.jobID),
+	}))
+
+	anyDocs := make([]*anypb.Any, 0, len(docs))
+	for _, doc := range docs {
+		anyDocs = append(anyDocs, &anypb.Any{Value: doc})
+	}
+
+	req := &golang.IngestRequest{Docs: anyDocs}
+	_, err := s.client.Ingest(grpcCtx, req)
+	if err == nil {
+		return nil
+	}
+
+	switch status.Code(err) {
+	case codes.ResourceExhausted:
+		// ResourceExhausted is also returned for rate limits and quotas, those are retried as is
+		if s.isMessageTooLarge(req, err) {
+			return fmt.Errorf("%w: %w", ErrBatchTooLarge, err)
+		}
+	case codes.InvalidArgument, codes.Unauthenticated, codes.PermissionDenied, codes.Unimplemented:
+		return PermanentSinkError{Err: err}
+	case codes.Unavailable:
+		s.reconnect()
+	default:
+		if errors.Is(err, io.EOF) {
+			s.reconnect()
+		}
+	}
+	return err
+}
+
+// isMessageTooLarge reports whether the ResourceExhausted err was returned for the size of req.
+func (s *grpcSink) isMessageTooLarge(req *golang.IngestRequest, err error) bool {
+	msg := strings.ToLower(status.Convert(err).Message())
+	if strings.Contains(msg, "larger than max") || strings.Contains(msg, "too large") {
+		return true
+	}
+	return proto.Size(req) > s.maxMessageBytes
+}
+
+// reconnect drops the connection, the next Ingest dials a new one.
+func (s *grpcSink) reconnect() {
+	if s.conn != nil {
+		_ = s.conn.Close()
+	}
+	s.conn = nil
+}
+
+// MaxBatchBytes leaves room for the envelope of the documents in the ingest request.
+func (s *grpcSink) MaxBatchBytes() int64 {
+	return int64(s.maxMessageBytes) * 9 / 10
+}
+
+func (s *grpcSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
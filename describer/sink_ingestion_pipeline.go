@@ -0,0 +1,159 @@
+package describer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+const DefaultIngestionPipelineService = "osis"
+
+// ingestionPipelineSink posts the documents to an OpenSearch Ingestion pipeline, or to a self-hosted
+// Data Prepper HTTP source. AWS pipelines are called with SigV4 signed requests, the others with basic
+// auth or a client certificate.
+type ingestionPipelineSink struct {
+	endpoint string
+	username string
+	password string
+
+	compression Compression
+
+	// signing is set for the AWS pipelines
+	signing     bool
+	region      string
+	service     string
+	credentials aws.CredentialsProvider
+
+	httpClient *http.Client
+	signer     *v4.Signer
+}
+
+func newIngestionPipelineSink(ctx context.Context, cfg SinkConfig) (*ingestionPipelineSink, error) {
+	if cfg.IngestionPipelineEndpoint == "" {
+		return nil, fmt.Errorf("the ingestion pipeline endpoint is required by the %s sink", SinkTypeIngestionPipeline)
+	}
+	httpClient, err := newHTTPSinkClient(cfg.OpenSearchTLS)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ingestionPipelineSink{
+		endpoint:    cfg.IngestionPipelineEndpoint,
+		username:    cfg.OpenSearchUsername,
+		password:    cfg.OpenSearchPassword,
+		compression: cfg.Compression,
+		httpClient:  httpClient,
+		signer:      v4.NewSigner(),
+	}
+	if s.username != "" {
+		return s, nil
+	}
+
+	region, service := awsRegionAndService(cfg.IngestionPipelineEndpoint)
+	if cfg.IngestionPipelineRegion != "" {
+		region = cfg.IngestionPipelineRegion
+	}
+	if cfg.IngestionPipelineService != "" {
+		service = cfg.IngestionPipelineService
+	}
+	if region == "" {
+		// not an AWS endpoint, send the documents without authentication
+		return s, nil
+	}
+	if service == "" {
+		service = DefaultIngestionPipelineService
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("load aws configuration: %w", err)
+	}
+	s.signing = true
+	s.region = region
+	s.service = service
+	s.credentials = awsCfg.Credentials
+	return s, nil
+}
+
+// awsRegionAndService extracts the region and the service of an AWS endpoint,
+// e.g. https://pipeline-id.us-east-2.osis.amazonaws.com/ingest. They are empty for the other endpoints.
+func awsRegionAndService(endpoint string) (string, string) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", ""
+	}
+	labels := strings.Split(u.Hostname(), ".")
+	if len(labels) < 4 || !strings.HasSuffix(u.Hostname(), ".amazonaws.com") {
+		return "", ""
+	}
+	return labels[len(labels)-4], labels[len(labels)-3]
+}
+
+func (s *ingestionPipelineSink) Ingest(ctx context.Context, docs []json.RawMessage) error {
+	body, err := json.Marshal(docs)
+	if err != nil {
+		return PermanentSinkError{Err: err}
+	}
+
+	// the signature covers the compressed body
+	body, err = compress(s.compression, body)
+	if err != nil {
+		return PermanentSinkError{Err: err}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return PermanentSinkError{Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.compression != CompressionNone {
+		req.Header.Set("Content-Encoding", string(s.compression))
+	}
+
+	if s.signing {
+		creds, err := s.credentials.Retrieve(ctx)
+		if err != nil {
+			return fmt.Errorf("retrieve aws credentials: %w", err)
+		}
+		err = s.signer.SignHTTP(ctx, creds, req, fmt.Sprintf("%x", sha256.Sum256(body)), s.service, s.region, time.Now())
+		if err != nil {
+			return fmt.Errorf("sign request: %w", err)
+		}
+	} else if s.username != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return httpSinkStatusError(resp.StatusCode, respBody)
+	}
+	// pipelines answering like the bulk API report the documents they failed to ingest
+	if bytes.HasPrefix(bytes.TrimSpace(respBody), []byte("{")) {
+		return parseBulkResponse(respBody)
+	}
+	return nil
+}
+
+func (s *ingestionPipelineSink) Close() error {
+	s.httpClient.CloseIdleConnections()
+	return nil
+}
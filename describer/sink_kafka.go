@@ -0,0 +1,71 @@
+package describer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/opengovern/og-util/pkg/es"
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaSink produces the documents to a Kafka topic, keyed by their id with their index in the
+// elasticsearch_index header, which is the format the ingestion stack consumes.
+type kafkaSink struct {
+	writer *kafka.Writer
+}
+
+func newKafkaSink(brokers []string, topic string, compression Compression) (*kafkaSink, error) {
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("%s is required by the %s sink", KafkaBrokersEnv, SinkTypeKafka)
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("%s is required by the %s sink", KafkaTopicEnv, SinkTypeKafka)
+	}
+	var codec kafka.Compression
+	switch compression {
+	case CompressionGzip:
+		codec = kafka.Gzip
+	case CompressionZstd:
+		codec = kafka.Zstd
+	}
+	return &kafkaSink{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			// the ResourceSender retries the failed batches itself
+			MaxAttempts: 1,
+			Compression: codec,
+		},
+	}, nil
+}
+
+func (s *kafkaSink) Ingest(ctx context.Context, docs []json.RawMessage) error {
+	msgs := make([]kafka.Message, 0, len(docs))
+	for _, doc := range docs {
+		m, err := parseDocMetadata(doc)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, kafka.Message{
+			Key:   []byte(m.EsID),
+			Value: doc,
+			Headers: []kafka.Header{
+				{Key: es.EsIndexHeader, Value: []byte(m.EsIndex)},
+			},
+		})
+	}
+	err := s.writer.WriteMessages(ctx, msgs...)
+	var tooLarge kafka.MessageTooLargeError
+	if errors.Is(err, kafka.MessageSizeTooLarge) || errors.As(err, &tooLarge) {
+		return fmt.Errorf("%w: %w", ErrBatchTooLarge, err)
+	}
+	return err
+}
+
+func (s *kafkaSink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,78 @@
+package describer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/opengovern/og-util/pkg/es"
+	"go.uber.org/zap"
+)
+
+// natsSink publishes the documents to a JetStream subject, with their index in the elasticsearch_index header.
+type natsSink struct {
+	conn    *nats.Conn
+	js      jetstream.JetStream
+	subject string
+}
+
+func newNATSSink(url, subject string, logger *zap.Logger) (*natsSink, error) {
+	if url == "" {
+		return nil, fmt.Errorf("%s is required by the %s sink", NATSURLEnv, SinkTypeNATS)
+	}
+	if subject == "" {
+		return nil, fmt.Errorf("%s is required by the %s sink", NATSSubjectEnv, SinkTypeNATS)
+	}
+
+	conn, err := nats.Connect(
+		url,
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			logger.Warn("nats sink got disconnected", zap.Error(err))
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &natsSink{conn: conn, js: js, subject: subject}, nil
+}
+
+func (s *natsSink) Ingest(ctx context.Context, docs []json.RawMessage) error {
+	acks := make([]jetstream.PubAckFuture, 0, len(docs))
+	for _, doc := range docs {
+		m, err := parseDocMetadata(doc)
+		if err != nil {
+			return err
+		}
+		msg := nats.NewMsg(s.subject)
+		msg.Header.Set(es.EsIndexHeader, m.EsIndex)
+		msg.Data = doc
+
+		ack, err := s.js.PublishMsgAsync(msg)
+		if err != nil {
+			return err
+		}
+		acks = append(acks, ack)
+	}
+
+	for _, ack := range acks {
+		select {
+		case <-ack.Ok():
+		case err := <-ack.Err():
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (s *natsSink) Close() error {
+	return s.conn.Drain()
+}
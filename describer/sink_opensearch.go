@@ -0,0 +1,183 @@
+package describer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPSinkTLS configures the TLS connections of the OpenSearch and ingestion pipeline sinks.
+type HTTPSinkTLS struct {
+	// CAFile is a PEM bundle of the certificate authorities trusted in addition to the system ones.
+	CAFile string
+	// CertFile and KeyFile are the client certificate of mTLS.
+	CertFile string
+	KeyFile  string
+}
+
+func newHTTPSinkClient(cfg HTTPSinkTLS) (*http.Client, error) {
+	if cfg.CAFile == "" && cfg.CertFile == "" {
+		return &http.Client{Timeout: 30 * time.Second}, nil
+	}
+
+	tlsConfig, err := loadTLSConfig(cfg.CAFile, cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Timeout: 30 * time.Second, Transport: transport}, nil
+}
+
+// openSearchSink bulk indexes the documents into an OpenSearch or Elasticsearch cluster.
+type openSearchSink struct {
+	addresses []string
+	username  string
+	password  string
+
+	compression Compression
+	httpClient  *http.Client
+	next        int
+}
+
+func newOpenSearchSink(cfg SinkConfig) (*openSearchSink, error) {
+	if len(cfg.OpenSearchAddresses) == 0 {
+		return nil, fmt.Errorf("%s is required by the %s sink", OpenSearchAddressesEnv, SinkTypeOpenSearch)
+	}
+	httpClient, err := newHTTPSinkClient(cfg.OpenSearchTLS)
+	if err != nil {
+		return nil, err
+	}
+	return &openSearchSink{
+		addresses:   cfg.OpenSearchAddresses,
+		username:    cfg.OpenSearchUsername,
+		password:    cfg.OpenSearchPassword,
+		compression: cfg.Compression,
+		httpClient:  httpClient,
+	}, nil
+}
+
+func (s *openSearchSink) Ingest(ctx context.Context, docs []json.RawMessage) error {
+	var body bytes.Buffer
+	for _, doc := range docs {
+		m, err := parseDocMetadata(doc)
+		if err != nil {
+			return err
+		}
+		action, err := json.Marshal(map[string]any{
+			"index": map[string]string{"_index": m.EsIndex, "_id": m.EsID},
+		})
+		if err != nil {
+			return err
+		}
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(doc)
+		body.WriteByte('\n')
+	}
+
+	// spread the batches over the nodes of the cluster
+	address := strings.TrimSuffix(s.addresses[s.next%len(s.addresses)], "/")
+	s.next++
+
+	payload, err := compress(s.compression, body.Bytes())
+	if err != nil {
+		return PermanentSinkError{Err: err}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, address+"/_bulk", bytes.NewReader(payload))
+	if err != nil {
+		return PermanentSinkError{Err: err}
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if s.compression != CompressionNone {
+		req.Header.Set("Content-Encoding", string(s.compression))
+	}
+	if s.username != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return httpSinkStatusError(resp.StatusCode, respBody)
+	}
+	return parseBulkResponse(respBody)
+}
+
+func (s *openSearchSink) Close() error {
+	s.httpClient.CloseIdleConnections()
+	return nil
+}
+
+// httpSinkStatusError is the error of a failed HTTP sink request, the client errors other than
+// throttling and too large requests are not retried.
+func httpSinkStatusError(statusCode int, body []byte) error {
+	err := fmt.Errorf("request failed with status %d: %s", statusCode, string(body))
+	if statusCode == http.StatusRequestEntityTooLarge {
+		return fmt.Errorf("%w: %w", ErrBatchTooLarge, err)
+	}
+	if statusCode >= 400 && statusCode < 500 && statusCode != http.StatusTooManyRequests && statusCode != http.StatusRequestTimeout {
+		return PermanentSinkError{Err: err}
+	}
+	return err
+}
+
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		ID     string `json:"_id"`
+		Status int    `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// parseBulkResponse returns a PartialSinkError listing the documents a bulk request failed to index, in
+// the order of the request. It is permanent if none of the failures is worth retrying.
+func parseBulkResponse(body []byte) error {
+	var resp bulkResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("parse bulk response: %w", err)
+	}
+	if !resp.Errors {
+		return nil
+	}
+
+	var failedDocs []int
+	var reasons []string
+	retryable := false
+	for i, item := range resp.Items {
+		for _, result := range item {
+			if result.Error == nil {
+				continue
+			}
+			failedDocs = append(failedDocs, i)
+			reasons = append(reasons, fmt.Sprintf("%s: %s: %s", result.ID, result.Error.Type, result.Error.Reason))
+			if result.Status == http.StatusTooManyRequests || result.Status >= 500 {
+				retryable = true
+			}
+		}
+	}
+
+	var err error = errors.New(strings.Join(reasons, "; "))
+	if !retryable {
+		err = PermanentSinkError{Err: err}
+	}
+	return PartialSinkError{FailedDocs: failedDocs, Err: err}
+}
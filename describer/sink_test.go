@@ -0,0 +1,167 @@
+package describer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/opengovern/og-util/proto/src/golang"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAWSRegionAndService(t *testing.T) {
+	for endpoint, want := range map[string][2]string{
+		"https://pipeline-abc.us-east-2.osis.amazonaws.com/ingest": {"us-east-2", "osis"},
+		"https://search-domain.eu-west-1.es.amazonaws.com":         {"eu-west-1", "es"},
+		"https://data-prepper.internal:2021/log/ingest":            {"", ""},
+	} {
+		region, service := awsRegionAndService(endpoint)
+		if region != want[0] || service != want[1] {
+			t.Errorf("%s: got %s/%s, want %s/%s", endpoint, region, service, want[0], want[1])
+		}
+	}
+}
+
+func TestParseBulkResponse(t *testing.T) {
+	batch := resourceBatch{Resources: []batchResource{
+		{ID: "a", Docs: []json.RawMessage{[]byte(`{}`), []byte(`{}`)}},
+		{ID: "b", Docs: []json.RawMessage{[]byte(`{}`), []byte(`{}`)}},
+	}}
+
+	err := parseBulkResponse([]byte(`{"errors":true,"items":[
+		{"index":{"_id":"1","status":201}},
+		{"index":{"_id":"2","status":201}},
+		{"index":{"_id":"3","status":429,"error":{"type":"es_rejected_execution_exception","reason":"queue full"}}},
+		{"index":{"_id":"4","status":201}}
+	]}`))
+	var partial PartialSinkError
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected a partial error, got %v", err)
+	}
+	if !isRetryableSendError(err) {
+		t.Error("expected a throttled document to be retried")
+	}
+
+	ingested, failed := batch.split(partial.FailedDocs)
+	if len(ingested.Resources) != 1 || ingested.Resources[0].ID != "a" || len(failed.Resources) != 1 || failed.Resources[0].ID != "b" {
+		t.Errorf("unexpected split: ingested %v, failed %v", ingested.ids(), failed.ids())
+	}
+
+	err = parseBulkResponse([]byte(`{"errors":true,"items":[{"index":{"_id":"1","status":400,"error":{"type":"mapper_parsing_exception","reason":"bad"}}}]}`))
+	if isRetryableSendError(err) {
+		t.Error("expected a rejected document not to be retried")
+	}
+	if err := parseBulkResponse([]byte(`{"errors":false,"items":[]}`)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// limitedSink rejects the requests with more than maxDocs documents.
+type limitedSink struct {
+	maxDocs  int
+	ingested int
+}
+
+func (s *limitedSink) Ingest(_ context.Context, docs []json.RawMessage) error {
+	if len(docs) > s.maxDocs {
+		return fmt.Errorf("%w: %d documents", ErrBatchTooLarge, len(docs))
+	}
+	s.ingested += len(docs)
+	return nil
+}
+
+func (s *limitedSink) Close() error {
+	return nil
+}
+
+func TestResourceSenderSplitsLargeBatches(t *testing.T) {
+	sink := &limitedSink{maxDocs: 8}
+	rs := NewResourceSender(context.Background(), sink, 1, zap.NewNop())
+	for i := 0; i < 20; i++ {
+		err := rs.Send(&golang.AzureResource{
+			UniqueId:        fmt.Sprintf("/subscriptions/s/resourceGroups/rg/providers/p/t/r%d", i),
+			DescriptionJson: `{}`,
+			Job:             &golang.DescribeJob{JobId: 1, ResourceType: "Microsoft.P/t"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rs.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := rs.Send(&golang.AzureResource{}); err == nil {
+		t.Error("expected a closed sender to fail")
+	}
+
+	stats := rs.Stats()
+	if len(rs.GetResourceIDs()) != 20 || sink.ingested != 40 {
+		t.Errorf("expected all the resources to be ingested, got %d resources and %d documents", len(rs.GetResourceIDs()), sink.ingested)
+	}
+	if stats.SplitBatches == 0 || stats.UndeliveredResources != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestCompress(t *testing.T) {
+	body := []byte(`{"a":"` + strings.Repeat("b", 1000) + `"}`)
+	for _, c := range []Compression{CompressionGzip, CompressionZstd} {
+		out, err := compress(c, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var r io.Reader
+		if c == CompressionGzip {
+			r, err = gzip.NewReader(bytes.NewReader(out))
+		} else {
+			r, err = zstdCompressor{}.Decompress(bytes.NewReader(out))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil || !bytes.Equal(got, body) {
+			t.Errorf("%s: round trip failed: %v", c, err)
+		}
+	}
+	if _, err := ParseCompression("brotli"); err == nil {
+		t.Error("expected an unsupported compression to fail")
+	}
+}
+
+type exhaustedSinkClient struct {
+	message string
+}
+
+func (c exhaustedSinkClient) Ingest(context.Context, *golang.IngestRequest, ...grpc.CallOption) (*golang.ResponseOK, error) {
+	return nil, status.Error(codes.ResourceExhausted, c.message)
+}
+
+func TestGRPCSinkResourceExhausted(t *testing.T) {
+	docs := []json.RawMessage{json.RawMessage(`{"id":"a"}`)}
+	for _, tt := range []struct {
+		message  string
+		tooLarge bool
+	}{
+		{message: "grpc: received message larger than max (5000000 vs. 4194304)", tooLarge: true},
+		{message: "rate limit exceeded, retry later", tooLarge: false},
+	} {
+		s := &grpcSink{maxMessageBytes: DefaultGRPCMaxMessageBytes, conn: &grpc.ClientConn{}, client: exhaustedSinkClient{message: tt.message}}
+		err := s.Ingest(context.Background(), docs)
+		if got := errors.Is(err, ErrBatchTooLarge); got != tt.tooLarge {
+			t.Errorf("%s: got too large %v, want %v", tt.message, got, tt.tooLarge)
+		}
+		if !tt.tooLarge && !isRetryableSendError(err) {
+			t.Errorf("%s: expected a retryable error, got %v", tt.message, err)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package describer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var errSpillQueueFull = errors.New("spill queue is full")
+
+// resourceBatch is a batch of resources sent to the sink together, each with its documents.
+type resourceBatch struct {
+	Resources []batchResource `json:"resources"`
+}
+
+type batchResource struct {
+	ID   string            `json:"id"`
+	Docs []json.RawMessage `json:"docs"`
+}
+
+func (b resourceBatch) ids() []string {
+	ids := make([]string, 0, len(b.Resources))
+	for _, r := range b.Resources {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func (b resourceBatch) docs() []json.RawMessage {
+	var docs []json.RawMessage
+	for _, r := range b.Resources {
+		docs = append(docs, r.Docs...)
+	}
+	return docs
+}
+
+func (b resourceBatch) size() int64 {
+	var size int64
+	for _, r := range b.Resources {
+		for _, doc := range r.Docs {
+			size += int64(len(doc))
+		}
+	}
+	return size
+}
+
+// split splits the batch between the resources whose documents were all ingested and the ones
+// having one of failedDocs, the indexes of the failed documents in docs().
+func (b resourceBatch) split(failedDocs []int) (ingested, failed resourceBatch) {
+	isFailed := map[int]bool{}
+	for _, i := range failedDocs {
+		isFailed[i] = true
+	}
+
+	i := 0
+	for _, r := range b.Resources {
+		ok := true
+		for range r.Docs {
+			if isFailed[i] {
+				ok = false
+			}
+			i++
+		}
+		if ok {
+			ingested.Resources = append(ingested.Resources, r)
+		} else {
+			failed.Resources = append(failed.Resources, r)
+		}
+	}
+	return ingested, failed
+}
+
+// spillQueue is a FIFO of the batches the sink failed to acknowledge, kept on disk until they can be retried.
+// It holds at most maxBytes of documents.
+type spillQueue struct {
+	dir      string
+	maxBytes int64
+
+	size  int64
+	seq   int
+	files []spilledFile
+}
+
+type spilledFile struct {
+	path string
+	size int64
+}
+
+func newSpillQueue(baseDir string, maxBytes int64) (*spillQueue, error) {
+	if baseDir == "" {
+		baseDir = os.TempDir()
+	}
+	dir, err := os.MkdirTemp(baseDir, "og-azure-describer-spill-")
+	if err != nil {
+		return nil, fmt.Errorf("create spill directory: %w", err)
+	}
+	return &spillQueue{dir: dir, maxBytes: maxBytes}, nil
+}
+
+func (q *spillQueue) Len() int {
+	return len(q.files)
+}
+
+func (q *spillQueue) Push(batch resourceBatch) error {
+	size := batch.size()
+	if q.size+size > q.maxBytes {
+		return errSpillQueueFull
+	}
+
+	content, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	q.seq++
+	path := filepath.Join(q.dir, fmt.Sprintf("batch-%06d.json", q.seq))
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("write spilled batch: %w", err)
+	}
+
+	q.files = append(q.files, spilledFile{path: path, size: size})
+	q.size += size
+	return nil
+}
+
+// Peek reads the oldest batch of the queue without removing it.
+func (q *spillQueue) Peek() (resourceBatch, error) {
+	var batch resourceBatch
+	if len(q.files) == 0 {
+		return batch, errors.New("spill queue is empty")
+	}
+
+	content, err := os.ReadFile(q.files[0].path)
+	if err != nil {
+		return batch, fmt.Errorf("read spilled batch: %w", err)
+	}
+	if err := json.Unmarshal(content, &batch); err != nil {
+		return batch, fmt.Errorf("parse spilled batch: %w", err)
+	}
+	return batch, nil
+}
+
+// Pop removes the oldest batch of the queue.
+func (q *spillQueue) Pop() {
+	if len(q.files) == 0 {
+		return
+	}
+	_ = os.Remove(q.files[0].path)
+	q.size -= q.files[0].size
+	q.files = q.files[1:]
+}
+
+// Close removes the queue and the batches left in it.
+func (q *spillQueue) Close() error {
+	q.files = nil
+	q.size = 0
+	return os.RemoveAll(q.dir)
+}
@@ -0,0 +1,107 @@
+package describer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	GRPCTLSEnv                = "DESCRIBER_GRPC_TLS"
+	GRPCCAFileEnv             = "DESCRIBER_GRPC_CA_FILE"
+	GRPCServerNameEnv         = "DESCRIBER_GRPC_SERVER_NAME"
+	GRPCCertFileEnv           = "DESCRIBER_GRPC_CERT_FILE"
+	GRPCKeyFileEnv            = "DESCRIBER_GRPC_KEY_FILE"
+	GRPCInsecureSkipVerifyEnv = "DESCRIBER_GRPC_INSECURE_SKIP_VERIFY"
+	// DevModeEnv allows the insecure settings meant for local development only.
+	DevModeEnv = "DESCRIBER_DEV_MODE"
+)
+
+var ErrInsecureTLS = errors.New("TLS certificate verification can only be disabled in dev mode")
+
+// GRPCTLSConfig configures the TLS connections to the scheduler and to the gRPC sink.
+type GRPCTLSConfig struct {
+	// Enabled forces TLS without endpoint auth, it is always used with endpoint auth so that the token is never
+	// sent in plaintext.
+	Enabled bool
+	// CAFile is a PEM bundle of the certificate authorities trusted in addition to the system ones.
+	CAFile string
+	// ServerName overrides the name the server certificate is verified against.
+	ServerName string
+	// CertFile and KeyFile are the client certificate of mTLS.
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify disables the verification of the server certificate, it is rejected unless DevMode is set.
+	InsecureSkipVerify bool
+	DevMode            bool
+}
+
+func GRPCTLSConfigFromEnv() GRPCTLSConfig {
+	return GRPCTLSConfig{
+		Enabled:            envBool(GRPCTLSEnv),
+		CAFile:             os.Getenv(GRPCCAFileEnv),
+		ServerName:         os.Getenv(GRPCServerNameEnv),
+		CertFile:           os.Getenv(GRPCCertFileEnv),
+		KeyFile:            os.Getenv(GRPCKeyFileEnv),
+		InsecureSkipVerify: envBool(GRPCInsecureSkipVerifyEnv),
+		DevMode:            envBool(DevModeEnv),
+	}
+}
+
+// TransportCredentials returns the credentials of a gRPC connection, TLS if endpointAuth is set or the
+// configuration asks for it and plaintext otherwise.
+func (c GRPCTLSConfig) TransportCredentials(endpointAuth bool) (credentials.TransportCredentials, error) {
+	if !endpointAuth && !c.Enabled && c.CAFile == "" && c.CertFile == "" {
+		return insecure.NewCredentials(), nil
+	}
+	if c.InsecureSkipVerify && !c.DevMode {
+		return nil, ErrInsecureTLS
+	}
+
+	tlsConfig, err := loadTLSConfig(c.CAFile, c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.ServerName = c.ServerName
+	tlsConfig.InsecureSkipVerify = c.InsecureSkipVerify
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// loadTLSConfig builds a client TLS configuration trusting the system and the caFile certificate authorities,
+// with the client certificate of certFile and keyFile if set.
+func loadTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+func envBool(name string) bool {
+	v, _ := strconv.ParseBool(os.Getenv(name))
+	return v
+}
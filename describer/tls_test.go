@@ -0,0 +1,25 @@
+package describer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGRPCTLSConfig(t *testing.T) {
+	creds, err := GRPCTLSConfig{}.TransportCredentials(false)
+	if err != nil || creds.Info().SecurityProtocol != "insecure" {
+		t.Errorf("expected plaintext without endpoint auth, got %v, %v", creds, err)
+	}
+
+	creds, err = GRPCTLSConfig{ServerName: "scheduler.internal"}.TransportCredentials(true)
+	if err != nil || creds.Info().SecurityProtocol != "tls" || creds.Info().ServerName != "scheduler.internal" {
+		t.Errorf("expected TLS with endpoint auth, got %v, %v", creds, err)
+	}
+
+	if _, err := (GRPCTLSConfig{InsecureSkipVerify: true}).TransportCredentials(true); !errors.Is(err, ErrInsecureTLS) {
+		t.Errorf("expected skipping the verification to fail outside dev mode, got %v", err)
+	}
+	if _, err := (GRPCTLSConfig{InsecureSkipVerify: true, DevMode: true}).TransportCredentials(true); err != nil {
+		t.Errorf("expected skipping the verification to be allowed in dev mode, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package describer
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -22,28 +23,112 @@ func fixAzureLocation(l string) string {
 	return strings.ToLower(strings.ReplaceAll(l, " ", ""))
 }
 
-func trimEmptyMaps(input map[string]any) {
-	for key, value := range input {
-		switch value.(type) {
-		case map[string]any:
-			if len(value.(map[string]any)) != 0 {
-				trimEmptyMaps(value.(map[string]any))
-			}
-			if len(value.(map[string]any)) == 0 {
-				delete(input, key)
+// trimJsonFromEmptyObjects drops the members of the objects whose value is an empty object, once the same
+// is done to it, e.g. {"a":{"b":{}},"c":[{}]} becomes {"c":[{}]}. The objects in arrays are kept as is.
+// It works on the bytes in a single pass instead of decoding the description.
+func trimJsonFromEmptyObjects(input []byte) ([]byte, error) {
+	if !json.Valid(input) {
+		return nil, fmt.Errorf("invalid json")
+	}
+	i := skipJSONSpace(input, 0)
+	if bytes.HasPrefix(input[i:], []byte("null")) {
+		return input, nil
+	}
+	if input[i] != '{' {
+		return nil, fmt.Errorf("json is not an object")
+	}
+	out, _ := appendTrimmedObject(make([]byte, 0, len(input)), input, i)
+	return out, nil
+}
+
+// appendTrimmedObject appends the valid object starting at input[i] to out without its empty objects, it
+// returns the index following the object.
+func appendTrimmedObject(out, input []byte, i int) ([]byte, int) {
+	out = append(out, '{')
+	i++
+	first := true
+	for {
+		i = skipJSONSpace(input, i)
+		if input[i] == '}' {
+			return append(out, '}'), i + 1
+		}
+		if input[i] == ',' {
+			i = skipJSONSpace(input, i+1)
+		}
+
+		mark := len(out)
+		if !first {
+			out = append(out, ',')
+		}
+		keyEnd := skipJSONString(input, i)
+		out = append(out, input[i:keyEnd]...)
+		out = append(out, ':')
+		i = skipJSONSpace(input, keyEnd)
+		i = skipJSONSpace(input, i+1)
+
+		if input[i] == '{' {
+			objectStart := len(out)
+			out, i = appendTrimmedObject(out, input, i)
+			if len(out)-objectStart == 2 {
+				out = out[:mark]
+				continue
 			}
+		} else {
+			valueEnd := skipJSONValue(input, i)
+			out = append(out, input[i:valueEnd]...)
+			i = valueEnd
 		}
+		first = false
 	}
 }
 
-func trimJsonFromEmptyObjects(input []byte) ([]byte, error) {
-	unknownData := map[string]any{}
-	err := json.Unmarshal(input, &unknownData)
-	if err != nil {
-		return nil, err
+func skipJSONSpace(input []byte, i int) int {
+	for i < len(input) && (input[i] == ' ' || input[i] == '\t' || input[i] == '\n' || input[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+// skipJSONString returns the index following the string starting at input[i].
+func skipJSONString(input []byte, i int) int {
+	for i++; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return i
+}
+
+// skipJSONValue returns the index following the value starting at input[i].
+func skipJSONValue(input []byte, i int) int {
+	switch input[i] {
+	case '"':
+		return skipJSONString(input, i)
+	case '{', '[':
+		depth := 0
+		for ; i < len(input); i++ {
+			switch input[i] {
+			case '"':
+				i = skipJSONString(input, i) - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+		}
+		return i
+	default:
+		for i < len(input) && strings.IndexByte(",}] \t\n\r", input[i]) < 0 {
+			i++
+		}
+		return i
 	}
-	trimEmptyMaps(unknownData)
-	return json.Marshal(unknownData)
 }
 
 func doDescribeAzure(
@@ -51,14 +136,21 @@ func doDescribeAzure(
 	logger *zap.Logger,
 	job describe.DescribeJob,
 	config map[string]any,
-	grpcEndpoint string,
-	ingestionPipelineEndpoint string,
-	describeToken string,
-	useOpenSearch bool) ([]string, error) {
-	rs, err := NewResourceSender(grpcEndpoint, ingestionPipelineEndpoint, describeToken, job.JobID, useOpenSearch, logger)
+	sinkConfig SinkConfig) ([]string, error) {
+	sink, err := NewSink(ctx, sinkConfig, job.JobID, logger)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to resource sender: %w", err)
+		return nil, fmt.Errorf("failed to connect to resource sink: %w", err)
+	}
+	rs := NewResourceSender(ctx, sink, job.JobID, logger)
+	// the resources described before a failure are still delivered, the deadline applies even if the job was cancelled
+	closeSender := func() error {
+		closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), CloseTimeout)
+		defer cancel()
+		return rs.Close(closeCtx)
 	}
+	// the sender is closed before its stats are read below, the deferred call only flushes it on the early
+	// returns and is a no-op otherwise
+	defer closeSender()
 
 	plg := steampipe.Plugin()
 	plgAD := steampipe.ADPlugin()
@@ -66,11 +158,36 @@ func doDescribeAzure(
 	if err != nil {
 		return nil, fmt.Errorf("azure subscription credentials: %w", err)
 	}
+	authType := creds.AuthType
+	if authType == "" {
+		authType = string(azure.AuthEnv)
+	}
 	subscriptionId := job.AccountID
 	if len(subscriptionId) == 0 {
 		subscriptionId = creds.SubscriptionID
 	}
 
+	authConfig := azure.AuthConfig{
+		TenantID:                     creds.TenantID,
+		ClientID:                     creds.ClientID,
+		ClientSecret:                 creds.ClientSecret,
+		CertificatePath:              creds.CertificatePath,
+		CertificatePassword:          creds.CertificatePass,
+		Username:                     creds.Username,
+		Password:                     creds.Password,
+		FederatedTokenFile:           creds.FederatedTokenFile,
+		EnvironmentName:              creds.EnvironmentName,
+		ResourceManagerEndpoint:      creds.ResourceManagerEndpoint,
+		ResourceManagerAudience:      creds.ResourceManagerAudience,
+		ActiveDirectoryAuthorityHost: creds.ActiveDirectoryAuthorityHost,
+		GraphEndpoint:                creds.GraphEndpoint,
+		StorageEndpointSuffix:        creds.StorageEndpointSuffix,
+	}
+	cloud, err := azure.CloudFromConfig(authConfig)
+	if err != nil {
+		return nil, fmt.Errorf("azure cloud: %w", err)
+	}
+
 	f := func(resource describer.Resource) error {
 		if resource.Description == nil {
 			return nil
@@ -91,7 +208,7 @@ func doDescribeAzure(
 			Name:             resource.Name,
 			SubscriptionID:   job.AccountID,
 			Location:         resource.Location,
-			CloudEnvironment: "AzurePublicCloud",
+			CloudEnvironment: cloud.Name,
 			ResourceType:     strings.ToLower(job.ResourceType),
 			SourceID:         job.SourceID,
 		}
@@ -106,12 +223,6 @@ func doDescribeAzure(
 			return fmt.Errorf("unmarshal metadata: %v", err.Error())
 		}
 
-		desc := resource.Description
-		err = json.Unmarshal(descriptionJSON, &desc)
-		if err != nil {
-			return fmt.Errorf("unmarshal description: %v", err.Error())
-		}
-
 		kafkaResource := Resource{
 			ID:            resource.UniqueID(),
 			Name:          resource.Name,
@@ -122,7 +233,7 @@ func doDescribeAzure(
 			ResourceJobID: job.JobID,
 			SourceID:      job.SourceID,
 			CreatedAt:     job.DescribedAt,
-			Description:   desc,
+			Description:   json.RawMessage(descriptionJSON),
 			Metadata:      metadata,
 		}
 
@@ -135,7 +246,7 @@ func doDescribeAzure(
 			kafkaResource.Metadata["name"] = name
 		}
 
-		rs.Send(&golang.AzureResource{
+		return rs.Send(&golang.AzureResource{
 			UniqueId:        resource.UniqueID(),
 			Id:              resource.ID,
 			Name:            resource.Name,
@@ -158,46 +269,98 @@ func doDescribeAzure(
 				RetryCounter: uint32(job.RetryCounter),
 			},
 		})
-		return nil
 	}
 	clientStream := (*describer.StreamSender)(&f)
 
+	// Failing sub-scopes (a storage account, a key vault, ...) are skipped and reported instead of failing the whole job
+	failures := &describer.PartialFailures{}
+	ctx = describer.WithPartialFailures(ctx, failures)
+	ctx = describer.WithSubscriptionFanOut(ctx, describer.SubscriptionFanOut{ContinueOnFailure: true})
+	// the throttler is shared by the jobs of the process, the stats of this job are counted apart
+	throttlingCounters := &describer.ThrottlingCounters{}
+	ctx = describer.WithThrottlingCounters(ctx, throttlingCounters)
+	dedup := describer.NewDeduplicator()
+	ctx = describer.WithDeduplicator(ctx, dedup)
+
 	_, err = azure.GetResources(
 		ctx,
 		logger,
 		job.ResourceType,
 		job.TriggerType,
 		[]string{subscriptionId},
-		azure.AuthConfig{
-			TenantID:            creds.TenantID,
-			ClientID:            creds.ClientID,
-			ClientSecret:        creds.ClientSecret,
-			CertificatePath:     creds.CertificatePath,
-			CertificatePassword: creds.CertificatePass,
-			Username:            creds.Username,
-			Password:            creds.Password,
-		},
-		string(azure.AuthEnv),
+		authConfig,
+		authType,
 		"",
 		clientStream,
 	)
-	if err != nil {
-		return nil, err
+	// flush the remaining batches so that the stats and the resource IDs below are final
+	if closeErr := closeSender(); closeErr != nil {
+		logger.Error("failed to flush the described resources", zap.Uint("jobID", job.JobID), zap.Error(closeErr))
+	}
+
+	senderStats := rs.Stats()
+	logger.Info("resource sender stats",
+		zap.Uint("jobID", job.JobID),
+		zap.Int64("batches", senderStats.Batches),
+		zap.Int64("splitBatches", senderStats.SplitBatches),
+		zap.Int64("maxQueueDepth", senderStats.MaxQueueDepth),
+		zap.Int64("acknowledgedResources", senderStats.AcknowledgedResources),
+		zap.Int64("undeliveredResources", senderStats.UndeliveredResources),
+		zap.Int64("duplicateResources", dedup.Dropped()),
+	)
+
+	stats := throttlingCounters.Stats()
+	logger.Info("arm throttling stats",
+		zap.Uint("jobID", job.JobID),
+		zap.Int64("requests", stats.Requests),
+		zap.Int64("throttledResponses", stats.ThrottledResponses),
+		zap.Int64("lowQuotaResponses", stats.LowQuotaResponses),
+		zap.Int64("waits", stats.Waits),
+		zap.Duration("waitDuration", stats.WaitDuration),
+	)
+
+	resourceIDs := rs.GetResourceIDs()
+	failedScopes := failures.Scopes()
+	if undelivered := rs.UndeliveredCount(); undelivered > 0 {
+		failedScopes = append(failedScopes, describer.FailedScope{
+			Type: describer.ScopeTypeSubscription,
+			ID:   subscriptionId,
+			Err:  fmt.Errorf("%d resources were not delivered", undelivered),
+		})
+	}
+	// a job cut off by its timeout or the shutdown fails as such rather than as a partial result
+	if ctx.Err() != nil {
+		return resourceIDs, fmt.Errorf("describe job interrupted: %w", ctx.Err())
 	}
+	if err != nil {
+		if len(resourceIDs) == 0 && len(failedScopes) == 0 {
+			return nil, err
+		}
 
-	rs.Finish()
+		var subscriptionErrs describer.SubscriptionErrors
+		if !errors.As(err, &subscriptionErrs) {
+			subscriptionErrs = describer.SubscriptionErrors{{SubscriptionID: subscriptionId, Err: err}}
+		}
+		for _, subscriptionErr := range subscriptionErrs {
+			failedScopes = append(failedScopes, describer.FailedScope{
+				Type: describer.ScopeTypeSubscription,
+				ID:   subscriptionErr.SubscriptionID,
+				Err:  subscriptionErr.Err,
+			})
+		}
+	}
+	if len(failedScopes) > 0 {
+		return resourceIDs, &describer.PartialError{FailedScopes: failedScopes}
+	}
 
-	return rs.GetResourceIDs(), nil
+	return resourceIDs, nil
 }
 
 func Do(ctx context.Context,
 	vlt vault.VaultSourceConfig,
 	logger *zap.Logger,
 	job describe.DescribeJob,
-	grpcEndpoint string,
-	describeDeliverToken string,
-	ingestionPipelineEndpoint string,
-	useOpenSearch bool,
+	sinkConfig SinkConfig,
 ) (resourceIDs []string, err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -218,5 +381,5 @@ func Do(ctx context.Context,
 		return nil, fmt.Errorf("decrypt error: %w", err)
 	}
 
-	return doDescribeAzure(ctx, logger, job, config, grpcEndpoint, ingestionPipelineEndpoint, describeDeliverToken, useOpenSearch)
+	return doDescribeAzure(ctx, logger, job, config, sinkConfig)
 }
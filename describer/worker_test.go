@@ -0,0 +1,139 @@
+package describer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/opengovern/og-util/proto/src/golang"
+	"go.uber.org/zap"
+)
+
+// trimDecoded is the decoding implementation of trimJsonFromEmptyObjects, the reference of its tests and
+// benchmarks.
+func trimDecoded(input []byte) ([]byte, error) {
+	var trim func(map[string]any)
+	trim = func(m map[string]any) {
+		for k, v := range m {
+			if child, ok := v.(map[string]any); ok {
+				trim(child)
+				if len(child) == 0 {
+					delete(m, k)
+				}
+			}
+		}
+	}
+	data := map[string]any{}
+	if err := json.Unmarshal(input, &data); err != nil {
+		return nil, err
+	}
+	trim(data)
+	return json.Marshal(data)
+}
+
+// testDescription looks like the description of a virtual machine, with n data disks.
+func testDescription(n int) []byte {
+	var disks []string
+	for i := 0; i < n; i++ {
+		disks = append(disks, fmt.Sprintf(`{"Name":"disk-%d","Lun":%d,"ManagedDisk":{"ID":"/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/disks/disk-%d","SecurityProfile":{}},"Tags":{}}`, i, i, i))
+	}
+	return []byte(fmt.Sprintf(`{"VirtualMachine":{"ID":"/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm","Name":"vm \"quoted\" {braces}","Identity":{"UserAssignedIdentities":{}},"Properties":{"HardwareProfile":{"VMSize":"Standard_D2s_v3"},"DiagnosticsProfile":{"BootDiagnostics":{}},"StorageProfile":{"DataDisks":[%s],"OSDisk":{"DiskSizeGB":30,"Caching":null,"EncryptionSettings":{"DiskEncryptionKey":{}}}}},"Tags":{"env":"prod"},"Zones":[]},"ResourceGroup":"rg","ExtensionsSettings":{}}`, strings.Join(disks, ",")))
+}
+
+func TestTrimJsonFromEmptyObjects(t *testing.T) {
+	for _, input := range []string{
+		`{}`,
+		`{"a":{}}`,
+		`{"a":{"b":{"c":{}}},"d":1}`,
+		`{"a":[{}],"b":{"c":[{"d":{}}]}, "e" : { } , "f":"}{\"\\"}`,
+		`{"a":-1.5e3,"b":true,"c":null,"d":{"e":false,"f":{}}}`,
+		string(testDescription(3)),
+	} {
+		got, err := trimJsonFromEmptyObjects([]byte(input))
+		if err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+		want, err := trimDecoded([]byte(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var gotValue, wantValue any
+		if err := json.Unmarshal(got, &gotValue); err != nil {
+			t.Fatalf("%s: invalid output %s: %v", input, got, err)
+		}
+		_ = json.Unmarshal(want, &wantValue)
+		if !reflect.DeepEqual(gotValue, wantValue) {
+			t.Errorf("%s: got %s, want %s", input, got, want)
+		}
+	}
+
+	for _, input := range []string{`[]`, `{"a":`, `"a"`} {
+		if _, err := trimJsonFromEmptyObjects([]byte(input)); err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+}
+
+func BenchmarkTrimJsonFromEmptyObjects(b *testing.B) {
+	input := testDescription(50)
+	b.Run("SinglePass", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(input)))
+		for i := 0; i < b.N; i++ {
+			if _, err := trimJsonFromEmptyObjects(input); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Decoded", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(input)))
+		for i := 0; i < b.N; i++ {
+			if _, err := trimDecoded(input); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkToBatchResource(b *testing.B) {
+	rs := &ResourceSender{logger: zap.NewNop()}
+	resource := &golang.AzureResource{
+		UniqueId:        "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm",
+		DescriptionJson: string(testDescription(50)),
+		Tags:            map[string]string{"env": "prod"},
+		Job:             &golang.DescribeJob{JobId: 1, ResourceType: "Microsoft.Compute/virtualMachines"},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, ok := rs.toBatchResource(resource); !ok {
+			b.Fatal("failed to encode the resource")
+		}
+	}
+}
+
+func BenchmarkCompress(b *testing.B) {
+	var docs []string
+	for i := 0; i < 100; i++ {
+		docs = append(docs, string(testDescription(10)))
+	}
+	body := []byte(strings.Join(docs, "\n"))
+	for _, c := range []Compression{CompressionGzip, CompressionZstd} {
+		b.Run(string(c), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			var size int
+			for i := 0; i < b.N; i++ {
+				out, err := compress(c, body)
+				if err != nil {
+					b.Fatal(err)
+				}
+				size = len(out)
+			}
+			b.ReportMetric(float64(len(body))/float64(size), "ratio")
+		})
+	}
+}
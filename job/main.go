@@ -3,86 +3,182 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
 	"github.com/opengovern/og-azure-describer/describer"
 	config2 "github.com/opengovern/og-util/pkg/config"
 	"github.com/opengovern/og-util/pkg/describe"
-	"github.com/opengovern/og-util/pkg/queue"
+	amqp "github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
 
+const (
+	defaultJobTimeout = 25 * time.Minute
+	consumerTag       = "og-azure-describer"
+)
+
 func main() {
-	j := DescriberJob{}
-	panic(j.Run())
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		panic(err)
+	}
+	defer logger.Sync()
+
+	j := DescriberJob{logger: logger}
+	if err := j.Run(ctx); err != nil {
+		logger.Fatal("describer job failed", zap.Error(err))
+	}
 }
 
 type DescriberConfig struct {
 	RabbitMQ  RabbitMQ
 	QueueName string
+	// Concurrency is the number of jobs run in parallel, 1 if not set.
+	Concurrency int
+	// JobTimeout is the timeout of a job, e.g. 30m, 25m if not set.
+	JobTimeout string `yaml:"job_timeout"`
 }
 
 type RabbitMQ struct {
 	Service  string
+	Port     int
 	Username string
 	Password string
+	// Prefetch is the number of unacknowledged messages delivered to the job, the concurrency if not set.
+	Prefetch int
 }
 
 type DescriberJob struct {
-	config      DescriberConfig
-	hopperQueue queue.Interface
+	config     DescriberConfig
+	logger     *zap.Logger
+	jobTimeout time.Duration
 }
 
-func (h *DescriberJob) Run() error {
+// Run consumes the describe jobs of the queue until ctx is done. The jobs being run when ctx is done are
+// cancelled like in local.Worker, the deliveries not yet started are requeued for the other consumers.
+func (h *DescriberJob) Run(ctx context.Context) error {
 	config2.ReadFromEnv(&h.config, nil)
+	if h.config.Concurrency <= 0 {
+		h.config.Concurrency = 1
+	}
+	if h.config.RabbitMQ.Prefetch <= 0 {
+		h.config.RabbitMQ.Prefetch = h.config.Concurrency
+	}
+	if h.config.RabbitMQ.Port == 0 {
+		h.config.RabbitMQ.Port = 5672
+	}
+	h.jobTimeout = defaultJobTimeout
+	if h.config.JobTimeout != "" {
+		d, err := time.ParseDuration(h.config.JobTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid JOB_TIMEOUT: %w", err)
+		}
+		h.jobTimeout = d
+	}
 
-	logger, err := zap.NewProduction()
+	h.logger.Info("connecting to RabbitMQ", zap.String("host", h.config.RabbitMQ.Service), zap.String("queue", h.config.QueueName))
+	conn, err := amqp.Dial(fmt.Sprintf("amqp://%s:%s@%s:%d/",
+		h.config.RabbitMQ.Username,
+		h.config.RabbitMQ.Password,
+		h.config.RabbitMQ.Service,
+		h.config.RabbitMQ.Port,
+	))
 	if err != nil {
-		return err
+		return fmt.Errorf("connecting to RabbitMQ: %w", err)
 	}
+	defer conn.Close()
 
-	logger.Info("Initializing the scheduler")
-	h.hopperQueue, err = initRabbitQueue(h.config.RabbitMQ, h.config.QueueName)
+	ch, err := conn.Channel()
 	if err != nil {
-		return err
+		return fmt.Errorf("creating channel: %w", err)
 	}
+	defer ch.Close()
 
-	ch, err := h.hopperQueue.Consume()
+	if err := ch.Qos(h.config.RabbitMQ.Prefetch, 0, false); err != nil {
+		return fmt.Errorf("setting prefetch: %w", err)
+	}
+	if _, err := ch.QueueDeclare(h.config.QueueName, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("declaring queue: %w", err)
+	}
+	deliveries, err := ch.ConsumeWithContext(ctx, h.config.QueueName, consumerTag, false, false, false, false, nil)
 	if err != nil {
-		return err
+		return fmt.Errorf("consuming queue: %w", err)
 	}
 
-	for msg := range ch {
-		var job describe.DescribeWorkerInput
-		if err := json.Unmarshal(msg.Body, &job); err != nil {
-			logger.Error("failed to consume message from DescribeWorkerInput", zap.Error(err))
-			err = msg.Nack(false, false)
-			if err != nil {
-				logger.Error("failure while sending nack for message", zap.Error(err))
+	h.logger.Info("consuming", zap.Int("concurrency", h.config.Concurrency), zap.Int("prefetch", h.config.RabbitMQ.Prefetch), zap.Duration("jobTimeout", h.jobTimeout))
+	var wg sync.WaitGroup
+	for i := 0; i < h.config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range deliveries {
+				if ctx.Err() != nil {
+					h.nack(msg, true)
+					continue
+				}
+				h.handle(ctx, msg)
 			}
-			continue
-		}
+		}()
+	}
+	wg.Wait()
 
-		err := describer.DescribeHandler(context.Background(), job)
-		if err != nil {
-			logger.Error("failure while handling job", zap.Uint("jobID", job.DescribeJob.JobID))
-			continue
-		}
+	if ctx.Err() == nil {
+		return errors.New("deliveries channel closed, the connection to RabbitMQ was lost")
 	}
+	h.logger.Info("consumer stopped")
 	return nil
 }
 
-func initRabbitQueue(cnf RabbitMQ, queueName string) (queue.Interface, error) {
-	qCfg := queue.Config{}
-	qCfg.Server.Username = cnf.Username
-	qCfg.Server.Password = cnf.Password
-	qCfg.Server.Host = cnf.Service
-	qCfg.Server.Port = 5672
-	qCfg.Queue.Name = queueName
-	qCfg.Queue.Durable = true
-	qCfg.Producer.ID = "describe-scheduler"
-	insightQueue, err := queue.New(qCfg)
+// handle runs the job of msg, acks it once its result is delivered and nacks it otherwise. The messages of
+// the jobs failing with a transient or throttling error, or whose result could not be delivered because of one,
+// are requeued once, the invalid ones are dropped.
+func (h *DescriberJob) handle(ctx context.Context, msg amqp.Delivery) {
+	var input describe.DescribeWorkerInput
+	if err := json.Unmarshal(msg.Body, &input); err != nil {
+		h.logger.Error("failed to consume message from DescribeWorkerInput", zap.Error(err))
+		h.nack(msg, false)
+		return
+	}
+
+	logger := h.logger.With(zap.Uint("id", input.DescribeJob.JobID), zap.String("type", input.DescribeJob.ResourceType), zap.String("account", input.DescribeJob.AccountID))
+	logger.Info("running job", zap.Bool("redelivered", msg.Redelivered))
+	startTime := time.Now()
+
+	jobCtx, cancel := context.WithTimeoutCause(ctx, h.jobTimeout, errors.New("describe job timed out"))
+	defer cancel()
+	result, err := describer.RunDescribeJob(jobCtx, logger, describer.TriggeredByRabbitMQ, input)
+
+	logger.Info("job completed", zap.Duration("duration", time.Since(startTime)), zap.String("status", result.Status))
+	retryable := result.Retryable()
 	if err != nil {
-		return nil, err
+		retryable = describer.IsRetryableError(err)
+	}
+	if err == nil && !retryable {
+		if err := msg.Ack(false); err != nil {
+			logger.Error("failed to ack message", zap.Error(err))
+		}
+		return
 	}
 
-	return insightQueue, nil
+	requeue := ctx.Err() != nil || (retryable && !msg.Redelivered)
+	if err == nil {
+		err = result.Err
+	}
+	logger.Error("failure while running job", zap.Error(err), zap.Bool("requeue", requeue))
+	h.nack(msg, requeue)
+}
+
+func (h *DescriberJob) nack(msg amqp.Delivery, requeue bool) {
+	if err := msg.Nack(false, requeue); err != nil {
+		h.logger.Error("failure while sending nack for message", zap.Error(err))
+	}
 }
@@ -0,0 +1,161 @@
+package local
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/opengovern/og-azure-describer/describer"
+	"github.com/opengovern/og-util/pkg/describe"
+	"github.com/opengovern/og-util/pkg/vault"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// StatusFileName is the file of the output directory the status records of the jobs are appended to.
+const StatusFileName = "status.jsonl"
+
+// RunJobsCommand runs the describe jobs of a file, e.g. from cron, without the scheduler, the job queue or the
+// ingestion stack. The resources of each job are written to job-<id>.jsonl in the output directory and the
+// outcome of the jobs to its status.jsonl.
+func RunJobsCommand() *cobra.Command {
+	var input, vaultFile, outDir string
+	cmd := &cobra.Command{
+		Use:   "run-jobs",
+		Short: "Run the describe jobs of a file without the scheduler",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			logger, err := zap.NewProduction()
+			if err != nil {
+				return err
+			}
+
+			var r io.Reader = os.Stdin
+			if input != "" && input != "-" {
+				f, err := os.Open(input)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				r = f
+			}
+			inputs, err := readJobInputs(r)
+			if err != nil {
+				return err
+			}
+
+			localVault, err := describer.NewLocalVault(vaultFile)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(outDir, 0o755); err != nil {
+				return err
+			}
+			statusFile, err := os.OpenFile(filepath.Join(outDir, StatusFileName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+			if err != nil {
+				return err
+			}
+			defer statusFile.Close()
+
+			failed := 0
+			for i, in := range inputs {
+				if err := cmd.Context().Err(); err != nil {
+					return err
+				}
+				if in.DescribeJob.JobID == 0 {
+					in.DescribeJob.JobID = uint(i + 1)
+				}
+				record := runJob(cmd.Context(), logger, localVault, vaultFile != "", in, outDir)
+				if record.Status == describer.DescribeResourceJobFailed {
+					failed++
+				}
+				b, err := json.Marshal(record)
+				if err != nil {
+					return err
+				}
+				if _, err := statusFile.Write(append(b, '\n')); err != nil {
+					return fmt.Errorf("write status record: %w", err)
+				}
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d jobs failed", failed, len(inputs))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&input, "input", "-", "File of the DescribeWorkerInput documents, a JSON array or one per line, - for stdin")
+	cmd.Flags().StringVar(&vaultFile, "vaultFile", "", "JSON file mapping the cipher texts of the jobs to their credentials")
+	cmd.Flags().StringVar(&outDir, "out-dir", ".", "Directory of the resources and the status records")
+	return cmd
+}
+
+// runJob runs a job with the vault of its input, or with the local vault if it has none or a vault file is given.
+func runJob(ctx context.Context, logger *zap.Logger, localVault *describer.LocalVault, preferLocal bool, input describe.DescribeWorkerInput, outDir string) describer.JobStatusRecord {
+	logger.Info("running job", zap.Uint("id", input.DescribeJob.JobID), zap.String("type", input.DescribeJob.ResourceType), zap.String("account", input.DescribeJob.AccountID))
+
+	var vlt vault.VaultSourceConfig = localVault
+	if input.VaultConfig.Provider != "" && !preferLocal {
+		vaultSc, err := describer.NewVaultSourceConfig(ctx, logger, input.VaultConfig)
+		if err != nil {
+			logger.Error("failed to initialize the vault of the job", zap.Error(err))
+			return describer.JobStatusRecord{
+				JobID:        input.DescribeJob.JobID,
+				ResourceType: input.DescribeJob.ResourceType,
+				AccountID:    input.DescribeJob.AccountID,
+				Status:       describer.DescribeResourceJobFailed,
+				Error:        err.Error(),
+			}
+		}
+		vlt = vaultSc
+	}
+
+	record := describer.RunLocalJob(ctx, logger, vlt, input.DescribeJob, outDir)
+	logger.Info("job completed", zap.Uint("id", record.JobID), zap.String("status", record.Status), zap.Int("resources", record.ResourceCount), zap.Duration("duration", record.FinishedAt.Sub(record.StartedAt)))
+	return record
+}
+
+// readJobInputs reads a JSON array of DescribeWorkerInput or a stream of them, e.g. one per line.
+func readJobInputs(r io.Reader) ([]describe.DescribeWorkerInput, error) {
+	br := bufio.NewReader(r)
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if b[0] != ' ' && b[0] != '\t' && b[0] != '\n' && b[0] != '\r' {
+			break
+		}
+		_, _ = br.ReadByte()
+	}
+
+	dec := json.NewDecoder(br)
+	if b, _ := br.Peek(1); b[0] == '[' {
+		var inputs []describe.DescribeWorkerInput
+		if err := dec.Decode(&inputs); err != nil {
+			return nil, fmt.Errorf("parse jobs: %w", err)
+		}
+		return inputs, nil
+	}
+
+	var inputs []describe.DescribeWorkerInput
+	for {
+		var in describe.DescribeWorkerInput
+		err := dec.Decode(&in)
+		if errors.Is(err, io.EOF) {
+			return inputs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse job %d: %w", len(inputs)+1, err)
+		}
+		inputs = append(inputs, in)
+	}
+}
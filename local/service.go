@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 	"github.com/opengovern/og-azure-describer/describer"
 	"github.com/opengovern/og-util/pkg/config"
@@ -16,6 +18,8 @@ import (
 	"go.uber.org/zap"
 	"os"
 	"runtime"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -27,19 +31,74 @@ const (
 	ConsumerGroupManuals = "azure-describer-manuals"
 )
 
-var (
-	ManualTriggers = os.Getenv("MANUAL_TRIGGERS")
-)
-
 type Config struct {
 	NATS config.NATS `koanf:"nats"`
+	// ManualTriggers consumes the jobs triggered manually rather than the scheduled ones.
+	ManualTriggers bool         `koanf:"manual_triggers"`
+	Worker         WorkerConfig `koanf:"worker"`
+}
+
+// WorkerConfig configures how the jobs are consumed, e.g. AZURE_DESCRIBER_WORKER__CONCURRENCY=4.
+type WorkerConfig struct {
+	// Concurrency is the number of jobs run in parallel.
+	Concurrency int `koanf:"concurrency"`
+	// JobTimeout is the timeout of the jobs of the resource types missing from JobTimeouts.
+	JobTimeout time.Duration `koanf:"job_timeout"`
+	// JobTimeouts are the timeouts of resource types as a comma separated list of type=duration,
+	// e.g. Microsoft.Storage/storageAccounts/blobs=2h.
+	JobTimeouts string `koanf:"job_timeouts"`
+	// AckWait is how long a message is redelivered after if the worker stops reporting its job in progress.
+	AckWait time.Duration `koanf:"ack_wait"`
+	// NakDelay is the delay of the redelivery of the jobs failed with a retryable error.
+	NakDelay time.Duration `koanf:"nak_delay"`
+	// MaxDeliver is the number of times a job is delivered, unlimited if not positive.
+	MaxDeliver int `koanf:"max_deliver"`
+}
+
+// DefaultConfig returns the configuration of the worker without any config file or environment variable.
+// MANUAL_TRIGGERS=true is still honored for the existing deployments.
+func DefaultConfig() Config {
+	return Config{
+		ManualTriggers: os.Getenv("MANUAL_TRIGGERS") == "true",
+		Worker: WorkerConfig{
+			Concurrency: 1,
+			JobTimeout:  25 * time.Minute,
+			AckWait:     5 * time.Minute,
+			NakDelay:    time.Minute,
+			MaxDeliver:  3,
+		},
+	}
+}
+
+// parseJobTimeouts parses WorkerConfig.JobTimeouts, the keys of the returned map are lower case.
+func parseJobTimeouts(s string) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		resourceType, timeout, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid job timeout %q, expected type=duration", entry)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(timeout))
+		if err != nil {
+			return nil, fmt.Errorf("invalid job timeout %q: %w", entry, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("invalid job timeout %q, the duration must be positive", entry)
+		}
+		timeouts[strings.ToLower(strings.TrimSpace(resourceType))] = d
+	}
+	return timeouts, nil
 }
 
 func WorkerCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
-			cnf := koanf.Provide("azure_describer", Config{})
+			cnf := koanf.Provide("azure_describer", DefaultConfig())
 			cmd.SilenceUsage = true
 			logger, err := zap.NewProduction()
 			if err != nil {
@@ -58,6 +117,7 @@ func WorkerCommand() *cobra.Command {
 			return w.Run(ctx)
 		},
 	}
+	cmd.AddCommand(RunJobsCommand())
 
 	return cmd
 }
@@ -67,8 +127,11 @@ type Worker struct {
 	logger   *zap.Logger
 	esClient opengovernance.Client
 	jq       *jq.JobQueue
+	js       jetstream.JetStream
 
 	esSinkClient esSinkClient.EsSinkServiceClient
+
+	jobTimeouts map[string]time.Duration
 }
 
 func NewWorker(
@@ -76,6 +139,17 @@ func NewWorker(
 	logger *zap.Logger,
 	ctx context.Context,
 ) (*Worker, error) {
+	if config.Worker.Concurrency < 1 {
+		return nil, fmt.Errorf("invalid worker concurrency %d", config.Worker.Concurrency)
+	}
+	if config.Worker.JobTimeout <= 0 || config.Worker.AckWait <= 0 {
+		return nil, errors.New("the job timeout and the ack wait of the worker must be positive")
+	}
+	jobTimeouts, err := parseJobTimeouts(config.Worker.JobTimeouts)
+	if err != nil {
+		return nil, err
+	}
+
 	jq, err := jq.New(config.NATS.URL, logger)
 	if err != nil {
 		logger.Error("failed to create job queue", zap.Error(err), zap.String("url", config.NATS.URL))
@@ -83,7 +157,7 @@ func NewWorker(
 	}
 
 	topic := JobQueueTopic
-	if ManualTriggers == "true" {
+	if config.ManualTriggers {
 		topic = JobQueueTopicManuals
 	}
 	if err := jq.Stream(ctx, StreamName, "azure describe job runner queue", []string{topic}, 200000); err != nil {
@@ -91,82 +165,230 @@ func NewWorker(
 		return nil, err
 	}
 
+	nc, err := nats.Connect(config.NATS.URL)
+	if err != nil {
+		logger.Error("failed to connect to NATS", zap.Error(err), zap.String("url", config.NATS.URL))
+		return nil, err
+	}
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return nil, err
+	}
+
 	w := &Worker{
-		config: config,
-		logger: logger,
-		jq:     jq,
+		config:      config,
+		logger:      logger,
+		jq:          jq,
+		js:          js,
+		jobTimeouts: jobTimeouts,
 	}
 
 	return w, nil
 }
 
+// Run consumes the jobs until ctx is done, running up to Concurrency of them in parallel. The messages of the
+// jobs are reported in progress until they are acked, so AckWait only bounds how long a crashed worker holds
+// a job. The jobs being run when ctx is done are cancelled.
 func (w *Worker) Run(ctx context.Context) error {
-	w.logger.Info("starting to consume")
+	w.logger.Info("starting to consume", zap.Int("concurrency", w.config.Worker.Concurrency))
 	topic := JobQueueTopic
-	consumer := ConsumerGroup
-	if ManualTriggers == "true" {
+	consumerGroup := ConsumerGroup
+	if w.config.ManualTriggers {
 		topic = JobQueueTopicManuals
-		consumer = ConsumerGroupManuals
+		consumerGroup = ConsumerGroupManuals
 	}
-	consumeCtx, err := w.jq.ConsumeWithConfig(ctx, consumer, StreamName, []string{topic}, jetstream.ConsumerConfig{
+
+	// the consumer of jq.ConsumeWithConfig pulls the messages ahead of the free slots, they would wait for
+	// one without being reported in progress, so the jobs are fetched from the same consumer directly.
+	consumer, err := w.js.CreateOrUpdateConsumer(ctx, StreamName, jetstream.ConsumerConfig{
+		Name:              fmt.Sprintf("%s-service", consumerGroup),
+		Description:       fmt.Sprintf("%s Service", strings.ToTitle(consumerGroup)),
+		FilterSubjects:    []string{topic},
 		Replicas:          1,
 		AckPolicy:         jetstream.AckExplicitPolicy,
 		DeliverPolicy:     jetstream.DeliverAllPolicy,
 		MaxAckPending:     -1,
-		AckWait:           time.Minute * 30,
+		AckWait:           w.config.Worker.AckWait,
+		MaxDeliver:        w.config.Worker.MaxDeliver,
 		InactiveThreshold: time.Hour,
-	}, []jetstream.PullConsumeOpt{
-		jetstream.PullMaxMessages(1),
-	}, func(msg jetstream.Msg) {
-		w.logger.Info("received a new job")
+	})
+	if err != nil {
+		return err
+	}
 
-		defer msg.Ack()
+	w.logger.Info("consuming")
+	w.consume(ctx, consumer, w.ProcessMessage)
+	return nil
+}
 
-		ctx, cancel := context.WithTimeoutCause(ctx, time.Minute*25, errors.New("describe worker timed out"))
-		defer cancel()
+// fetchMaxWait is how long a fetch waits for the jobs, and so how long a slot freed meanwhile stays idle.
+const fetchMaxWait = 10 * time.Second
 
-		if err := w.ProcessMessage(ctx, msg); err != nil {
-			w.logger.Error("failed to process message", zap.Error(err))
+// jobSource is the consumer the jobs are fetched from.
+type jobSource interface {
+	Fetch(batch int, opts ...jetstream.FetchOpt) (jetstream.MessageBatch, error)
+}
+
+// consume fetches as many jobs as there are free slots and runs them with process until ctx is done, then waits
+// for the running ones. A message is only fetched once a slot is free, so each one is reported in progress
+// from its delivery until it is settled.
+func (w *Worker) consume(ctx context.Context, source jobSource, process func(context.Context, jetstream.Msg) (describer.JobResult, error)) {
+	slots := make(chan struct{}, w.config.Worker.Concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return
 		}
-		err := msg.Ack()
+		free := 1
+	acquire:
+		for free < cap(slots) {
+			select {
+			case slots <- struct{}{}:
+				free++
+			default:
+				break acquire
+			}
+		}
+
+		batch, err := source.Fetch(free, jetstream.FetchMaxWait(fetchMaxWait))
 		if err != nil {
-			w.logger.Error("failed to ack message", zap.Error(err))
+			w.logger.Error("failed to fetch jobs", zap.Error(err))
+			for ; free > 0; free-- {
+				<-slots
+			}
+			select {
+			case <-time.After(time.Second):
+			case <-ctx.Done():
+			}
+			continue
 		}
 
-		w.logger.Info("processing a job completed")
-	})
-	if err != nil {
-		return err
-	}
+		for msg := range batch.Messages() {
+			free--
+			if ctx.Err() != nil {
+				<-slots
+				w.settle(ctx, msg, describer.JobResult{}, ctx.Err())
+				continue
+			}
+			w.logger.Info("received a new job")
 
-	w.logger.Info("consuming")
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
 
-	<-ctx.Done()
-	consumeCtx.Drain()
-	consumeCtx.Stop()
+				stopHeartbeat := w.heartbeat(msg)
+				result, err := process(ctx, msg)
+				stopHeartbeat()
+				if err != nil {
+					w.logger.Error("failed to process message", zap.Error(err))
+				}
+				w.settle(ctx, msg, result, err)
 
-	return nil
+				w.logger.Info("processing a job completed")
+			}()
+		}
+		if err := batch.Error(); err != nil {
+			w.logger.Warn("failed to fetch all the jobs", zap.Error(err))
+		}
+		for ; free > 0; free-- {
+			<-slots
+		}
+	}
 }
 
-func (w *Worker) ProcessMessage(ctx context.Context, msg jetstream.Msg) error {
+// ProcessMessage runs the job of msg with the timeout of its resource type. It returns an errInvalidJob
+// error if msg is not a job and the error of describer.RunDescribeJob otherwise.
+func (w *Worker) ProcessMessage(ctx context.Context, msg jetstream.Msg) (describer.JobResult, error) {
 	startTime := time.Now()
 	var input describe.DescribeWorkerInput
 	err := json.Unmarshal(msg.Data(), &input)
 	if err != nil {
-		return err
+		return describer.JobResult{}, fmt.Errorf("%w: %v", errInvalidJob, err)
 	}
 	runtime.GC()
 
-	w.logger.Info("running job", zap.Uint("id", input.DescribeJob.JobID), zap.String("type", input.DescribeJob.ResourceType), zap.String("account", input.DescribeJob.AccountID))
+	timeout := w.jobTimeout(input.DescribeJob.ResourceType)
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, errors.New("describe worker timed out"))
+	defer cancel()
 
-	err = describer.DescribeHandler(ctx, w.logger, describer.TriggeredByLocal, input)
+	w.logger.Info("running job", zap.Uint("id", input.DescribeJob.JobID), zap.String("type", input.DescribeJob.ResourceType), zap.String("account", input.DescribeJob.AccountID), zap.Duration("timeout", timeout))
+
+	result, err := describer.RunDescribeJob(ctx, w.logger, describer.TriggeredByLocal, input)
 	endTime := time.Now()
 
-	w.logger.Info("job completed", zap.Uint("id", input.DescribeJob.JobID), zap.String("type", input.DescribeJob.ResourceType), zap.String("account", input.DescribeJob.AccountID), zap.Duration("duration", endTime.Sub(startTime)))
+	w.logger.Info("job completed", zap.Uint("id", input.DescribeJob.JobID), zap.String("type", input.DescribeJob.ResourceType), zap.String("account", input.DescribeJob.AccountID), zap.String("status", result.Status), zap.Duration("duration", endTime.Sub(startTime)))
 	if err != nil {
 		w.logger.Error("failure while running job", zap.Error(err))
-		return err
+		return result, err
 	}
 
-	return nil
+	return result, nil
+}
+
+var errInvalidJob = errors.New("invalid describe job")
+
+// settle acks msg once its job succeeded or partially failed. The failed jobs are redelivered right away if
+// they were interrupted by the shutdown, after NakDelay if they failed with a retryable error or their result
+// could not be delivered because of one, and are not redelivered otherwise.
+func (w *Worker) settle(ctx context.Context, msg jetstream.Msg, result describer.JobResult, jobErr error) {
+	retryable := result.Retryable()
+	if jobErr != nil {
+		retryable = describer.IsRetryableError(jobErr)
+	}
+
+	var err error
+	switch {
+	case errors.Is(jobErr, errInvalidJob):
+		err = msg.Term()
+	case jobErr == nil && result.Status != describer.DescribeResourceJobFailed:
+		err = msg.Ack()
+	case ctx.Err() != nil:
+		err = msg.Nak()
+	case retryable:
+		err = msg.NakWithDelay(w.config.Worker.NakDelay)
+	default:
+		err = msg.Term()
+	}
+	if err != nil {
+		w.logger.Error("failed to ack message", zap.Error(err))
+	}
+}
+
+// heartbeat reports msg in progress every third of AckWait until the returned function is called and returns.
+func (w *Worker) heartbeat(msg jetstream.Msg) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(w.config.Worker.AckWait / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := msg.InProgress(); err != nil {
+					w.logger.Warn("failed to report the job in progress", zap.Error(err))
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-stopped
+	}
+}
+
+// jobTimeout returns the timeout of the jobs of resourceType.
+func (w *Worker) jobTimeout(resourceType string) time.Duration {
+	if timeout, ok := w.jobTimeouts[strings.ToLower(resourceType)]; ok {
+		return timeout
+	}
+	return w.config.Worker.JobTimeout
 }
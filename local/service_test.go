@@ -0,0 +1,174 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	azureDescriber "github.com/opengovern/og-azure-describer/azure/describer"
+	"github.com/opengovern/og-azure-describer/describer"
+	"go.uber.org/zap"
+)
+
+func TestJobTimeout(t *testing.T) {
+	jobTimeouts, err := parseJobTimeouts(" Microsoft.Storage/storageAccounts/blobs=2h, Microsoft.Compute/virtualMachines = 45m,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &Worker{config: DefaultConfig(), jobTimeouts: jobTimeouts}
+	for resourceType, want := range map[string]time.Duration{
+		"Microsoft.Storage/storageAccounts/blobs": 2 * time.Hour,
+		"microsoft.compute/virtualmachines":       45 * time.Minute,
+		"Microsoft.Network/virtualNetworks":       25 * time.Minute,
+	} {
+		if got := w.jobTimeout(resourceType); got != want {
+			t.Errorf("%s: got timeout %s, want %s", resourceType, got, want)
+		}
+	}
+
+	for _, s := range []string{"Microsoft.Compute/virtualMachines", "Microsoft.Compute/virtualMachines=soon", "Microsoft.Compute/virtualMachines=-1m"} {
+		if _, err := parseJobTimeouts(s); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+}
+
+type fakeMsg struct {
+	jetstream.Msg
+
+	mu         sync.Mutex
+	inProgress int
+	settled    string
+}
+
+func (m *fakeMsg) Data() []byte { return []byte("{}") }
+
+func (m *fakeMsg) InProgress() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inProgress++
+	return nil
+}
+
+func (m *fakeMsg) settle(how string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.settled = how
+	return nil
+}
+
+func (m *fakeMsg) Ack() error                         { return m.settle("ack") }
+func (m *fakeMsg) Nak() error                         { return m.settle("nak") }
+func (m *fakeMsg) NakWithDelay(_ time.Duration) error { return m.settle("nak with delay") }
+func (m *fakeMsg) Term() error                        { return m.settle("term") }
+
+type fakeBatch struct {
+	msgs chan jetstream.Msg
+}
+
+func (b fakeBatch) Messages() <-chan jetstream.Msg { return b.msgs }
+func (b fakeBatch) Error() error                   { return nil }
+
+// fakeSource hands out its messages and fails the test if more are fetched than there are free slots.
+type fakeSource struct {
+	t           *testing.T
+	concurrency int
+
+	mu      sync.Mutex
+	msgs    []*fakeMsg
+	running int
+	done    chan struct{}
+}
+
+func (s *fakeSource) Fetch(batch int, _ ...jetstream.FetchOpt) (jetstream.MessageBatch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running+batch > s.concurrency {
+		s.t.Errorf("fetched %d jobs with %d running", batch, s.running)
+	}
+	msgs := make(chan jetstream.Msg, batch)
+	for ; batch > 0 && len(s.msgs) > 0; batch-- {
+		msgs <- s.msgs[0]
+		s.msgs = s.msgs[1:]
+		s.running++
+	}
+	close(msgs)
+	return fakeBatch{msgs: msgs}, nil
+}
+
+func (s *fakeSource) finished() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running--
+}
+
+func TestConsumeReportsFetchedJobsInProgress(t *testing.T) {
+	config := DefaultConfig()
+	config.Worker.Concurrency = 2
+	config.Worker.AckWait = 30 * time.Millisecond
+	w := &Worker{config: config, logger: zap.NewNop()}
+
+	var msgs []*fakeMsg
+	for i := 0; i < 5; i++ {
+		msgs = append(msgs, &fakeMsg{})
+	}
+	source := &fakeSource{t: t, concurrency: config.Worker.Concurrency, msgs: msgs}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var processed sync.WaitGroup
+	processed.Add(len(msgs))
+	go func() {
+		processed.Wait()
+		cancel()
+	}()
+	w.consume(ctx, source, func(ctx context.Context, msg jetstream.Msg) (describer.JobResult, error) {
+		defer processed.Done()
+		defer source.finished()
+		time.Sleep(3 * config.Worker.AckWait)
+		return describer.JobResult{Status: describer.DescribeResourceJobSucceeded}, nil
+	})
+
+	for i, msg := range msgs {
+		if msg.inProgress == 0 || msg.settled != "ack" {
+			t.Errorf("job %d: reported in progress %d times and settled with %q", i, msg.inProgress, msg.settled)
+		}
+	}
+}
+
+func TestSettle(t *testing.T) {
+	w := &Worker{config: DefaultConfig(), logger: zap.NewNop()}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	failed := func(category azureDescriber.ErrorCategory) describer.JobResult {
+		return describer.JobResult{Status: describer.DescribeResourceJobFailed, ErrorCategory: string(category)}
+	}
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		result describer.JobResult
+		err    error
+		want   string
+	}{
+		{name: "succeeded", ctx: context.Background(), result: describer.JobResult{Status: describer.DescribeResourceJobSucceeded}, want: "ack"},
+		{name: "partial", ctx: context.Background(), result: describer.JobResult{Status: describer.DescribeResourceJobPartial}, want: "ack"},
+		{name: "throttled", ctx: context.Background(), result: failed(azureDescriber.ErrorCategoryThrottled), want: "nak with delay"},
+		{name: "forbidden", ctx: context.Background(), result: failed(azureDescriber.ErrorCategoryForbidden), want: "term"},
+		{name: "interrupted", ctx: cancelled, result: failed(azureDescriber.ErrorCategoryInternal), want: "nak"},
+		{name: "undelivered", ctx: context.Background(), result: failed(azureDescriber.ErrorCategoryForbidden), err: context.DeadlineExceeded, want: "nak with delay"},
+		{name: "invalid", ctx: cancelled, err: errInvalidJob, want: "term"},
+		{name: "setup failure", ctx: context.Background(), err: errors.New("boom"), want: "term"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &fakeMsg{}
+			w.settle(tt.ctx, msg, tt.result, tt.err)
+			if msg.settled != tt.want {
+				t.Errorf("settled with %q, want %q", msg.settled, tt.want)
+			}
+		})
+	}
+}